@@ -47,7 +47,7 @@ func run() error {
 	db := database.Get()
 	defer db.Close()
 
-	srv := server.New(db)
+	srv := server.New(ctx, db)
 
 	srvErr := make(chan error, 1)
 	go func() {
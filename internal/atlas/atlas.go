@@ -0,0 +1,144 @@
+// Package atlas decodes atlas passive tree codes as shared by the official
+// atlas tree planner.
+package atlas
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+var ErrInvalidCode = errors.New("invalid atlas tree code")
+
+// Decode parses a tree code or a planner URL ending in one. Codes of
+// version 4 and 5 list node ids up to the end of the data, version 6 adds
+// counted sections for nodes, cluster nodes and mastery effects.
+func Decode(code string) (models.AtlasTree, error) {
+	var tree models.AtlasTree
+
+	code = strings.TrimSpace(code)
+	if i := strings.IndexAny(code, "?#"); i >= 0 {
+		code = code[:i]
+	}
+	code = code[strings.LastIndex(code, "/")+1:]
+	code = strings.TrimRight(code, "=")
+
+	data, err := base64.RawURLEncoding.DecodeString(code)
+	if err != nil {
+		return tree, fmt.Errorf("%w: not base64", ErrInvalidCode)
+	}
+
+	r := reader{data: data}
+
+	version, ok := r.uint32()
+	if !ok {
+		return tree, fmt.Errorf("%w: too short", ErrInvalidCode)
+	}
+
+	tree.Version = int(version)
+	tree.Nodes = make([]int, 0)
+	tree.Masteries = make([]models.AtlasMastery, 0)
+
+	// class and ascendancy are always zero for the atlas
+	if !r.skip(2) {
+		return tree, fmt.Errorf("%w: too short", ErrInvalidCode)
+	}
+
+	switch version {
+	case 4, 5:
+		// fullscreen flag
+		if !r.skip(1) {
+			return tree, fmt.Errorf("%w: too short", ErrInvalidCode)
+		}
+
+		for r.remaining() >= 2 {
+			node, _ := r.uint16()
+			tree.Nodes = append(tree.Nodes, int(node))
+		}
+	case 6:
+		for _, section := range []string{"nodes", "cluster nodes"} {
+			n, ok := r.uint8()
+			if !ok {
+				return tree, fmt.Errorf("%w: missing %s", ErrInvalidCode, section)
+			}
+
+			for range n {
+				node, ok := r.uint16()
+				if !ok {
+					return tree, fmt.Errorf("%w: truncated %s", ErrInvalidCode, section)
+				}
+				if section == "nodes" {
+					tree.Nodes = append(tree.Nodes, int(node))
+				}
+			}
+		}
+
+		n, ok := r.uint8()
+		if !ok {
+			return tree, fmt.Errorf("%w: missing masteries", ErrInvalidCode)
+		}
+
+		for range n {
+			effect, ok1 := r.uint16()
+			node, ok2 := r.uint16()
+			if !ok1 || !ok2 {
+				return tree, fmt.Errorf("%w: truncated masteries", ErrInvalidCode)
+			}
+			tree.Masteries = append(tree.Masteries, models.AtlasMastery{Node: int(node), Effect: int(effect)})
+		}
+	default:
+		return tree, fmt.Errorf("%w: unsupported version %d", ErrInvalidCode, version)
+	}
+
+	tree.Code = code
+
+	return tree, nil
+}
+
+type reader struct {
+	data []byte
+	pos  int
+}
+
+func (r *reader) remaining() int {
+	return len(r.data) - r.pos
+}
+
+func (r *reader) skip(n int) bool {
+	if r.remaining() < n {
+		return false
+	}
+	r.pos += n
+	return true
+}
+
+func (r *reader) uint8() (uint8, bool) {
+	if r.remaining() < 1 {
+		return 0, false
+	}
+	v := r.data[r.pos]
+	r.pos++
+	return v, true
+}
+
+func (r *reader) uint16() (uint16, bool) {
+	if r.remaining() < 2 {
+		return 0, false
+	}
+	v := binary.BigEndian.Uint16(r.data[r.pos:])
+	r.pos += 2
+	return v, true
+}
+
+func (r *reader) uint32() (uint32, bool) {
+	if r.remaining() < 4 {
+		return 0, false
+	}
+	v := binary.BigEndian.Uint32(r.data[r.pos:])
+	r.pos += 4
+	return v, true
+}
@@ -0,0 +1,126 @@
+package atlas
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+// encode builds a tree code: the version, class and ascendancy, followed
+// by the given bytes and 16-bit values.
+func encode(version uint32, parts ...any) string {
+	data := binary.BigEndian.AppendUint32(nil, version)
+	data = append(data, 0, 0)
+
+	for _, p := range parts {
+		switch v := p.(type) {
+		case uint8:
+			data = append(data, v)
+		case uint16:
+			data = binary.BigEndian.AppendUint16(data, v)
+		}
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func TestDecode(t *testing.T) {
+	v4 := encode(4, uint8(0), uint16(100), uint16(200), uint16(300))
+	v5 := encode(5, uint8(1), uint16(65535))
+	v6 := encode(6,
+		uint8(2), uint16(10), uint16(20),
+		uint8(1), uint16(30),
+		uint8(2), uint16(7), uint16(10), uint16(8), uint16(20),
+	)
+
+	tests := []struct {
+		name  string
+		input string
+		want  models.AtlasTree
+	}{
+		{
+			name:  "version 4",
+			input: v4,
+			want:  models.AtlasTree{Version: 4, Code: v4, Nodes: []int{100, 200, 300}, Masteries: []models.AtlasMastery{}},
+		},
+		{
+			name:  "version 5",
+			input: v5,
+			want:  models.AtlasTree{Version: 5, Code: v5, Nodes: []int{65535}, Masteries: []models.AtlasMastery{}},
+		},
+		{
+			name:  "version 5 without nodes",
+			input: encode(5, uint8(0)),
+			want:  models.AtlasTree{Version: 5, Code: encode(5, uint8(0)), Nodes: []int{}, Masteries: []models.AtlasMastery{}},
+		},
+		{
+			name:  "version 4 ignores a trailing byte",
+			input: encode(4, uint8(0), uint16(100), uint8(9)),
+			want:  models.AtlasTree{Version: 4, Code: encode(4, uint8(0), uint16(100), uint8(9)), Nodes: []int{100}, Masteries: []models.AtlasMastery{}},
+		},
+		{
+			name:  "version 6 skips cluster nodes",
+			input: v6,
+			want: models.AtlasTree{
+				Version:   6,
+				Code:      v6,
+				Nodes:     []int{10, 20},
+				Masteries: []models.AtlasMastery{{Node: 10, Effect: 7}, {Node: 20, Effect: 8}},
+			},
+		},
+		{
+			name:  "planner url",
+			input: " https://www.pathofexile.com/fullscreen-atlas-skill-tree/" + v4 + "?accountName=x#top ",
+			want:  models.AtlasTree{Version: 4, Code: v4, Nodes: []int{100, 200, 300}, Masteries: []models.AtlasMastery{}},
+		},
+		{
+			name:  "padded code",
+			input: v5 + "==",
+			want:  models.AtlasTree{Version: 5, Code: v5, Nodes: []int{65535}, Masteries: []models.AtlasMastery{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.input)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"not base64", "not*base64!"},
+		{"too short for the version", base64.RawURLEncoding.EncodeToString([]byte{0, 0, 4})},
+		{"missing class and ascendancy", base64.RawURLEncoding.EncodeToString([]byte{0, 0, 0, 4, 0})},
+		{"unsupported version", encode(3, uint8(0))},
+		{"version 4 without fullscreen flag", encode(4)},
+		{"version 6 without nodes", encode(6)},
+		{"version 6 truncated nodes", encode(6, uint8(2), uint16(10))},
+		{"version 6 without cluster nodes", encode(6, uint8(1), uint16(10))},
+		{"version 6 truncated cluster nodes", encode(6, uint8(0), uint8(1))},
+		{"version 6 without masteries", encode(6, uint8(0), uint8(0))},
+		{"version 6 truncated masteries", encode(6, uint8(0), uint8(0), uint8(1), uint16(7))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.input); !errors.Is(err, ErrInvalidCode) {
+				t.Errorf("Decode() error = %v, want %v", err, ErrInvalidCode)
+			}
+		})
+	}
+}
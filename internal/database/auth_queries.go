@@ -1,13 +1,19 @@
 package database
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
-	"log/slog"
 	"time"
 
 	"github.com/Vyary/api/internal/models"
 )
 
+// ErrRefreshTokenReused is returned when a refresh token that was already
+// rotated is presented again, which means it was most likely stolen.
+var ErrRefreshTokenReused = errors.New("refresh token reused")
+
 func (s *libsqlDB) StoreOAuthToken(id string, token models.OAuthToken) error {
 	query := `
 	INSERT INTO users (id, username, access_token, expires_in, token_type, scope, sub)
@@ -46,14 +52,18 @@ func (s *libsqlDB) RemoveOAuthToken(id string) error {
 	return nil
 }
 
-func (s *libsqlDB) StoreRefreshToken(userID string, tokenID string, expiration time.Duration) error {
+// StoreRefreshToken records a refresh token as the current token of its
+// family. A family is the chain of tokens rotated from a single login: the
+// new token keeps the creation time of the family, while the last use and
+// the client are those of the login or refresh issuing it.
+func (s *libsqlDB) StoreRefreshToken(userID string, tokenID string, familyID string, expiration time.Duration, client models.SessionClient) error {
 	query := `
-	INSERT INTO refresh_tokens (user_id, token_id, expires_at) 
-	VALUES (?, ?, ?)`
+	INSERT INTO refresh_tokens (user_id, token_id, family_id, expires_at, created_at, last_used_at, user_agent, ip) 
+	VALUES (?, ?, ?, ?, COALESCE((SELECT MIN(created_at) FROM refresh_tokens WHERE user_id = ? AND family_id = ?), unixepoch()), unixepoch(), ?, ?)`
 
 	expiresAt := time.Now().Add(expiration)
 
-	_, err := s.db.Exec(query, userID, tokenID, expiresAt.Unix())
+	_, err := s.db.Exec(query, userID, tokenID, familyID, expiresAt.Unix(), userID, familyID, client.UserAgent, client.IP)
 	if err != nil {
 		return fmt.Errorf("failed to store refresh token: %w", err)
 	}
@@ -61,30 +71,52 @@ func (s *libsqlDB) StoreRefreshToken(userID string, tokenID string, expiration t
 	return nil
 }
 
-func (s *libsqlDB) IsRefreshTokenValid(tokenID string) bool {
+// RotateRefreshToken marks a refresh token as used so it cannot be exchanged
+// again. Rotated tokens are kept until they expire so a replay can be told
+// apart from a revoked token: it returns ErrRefreshTokenReused when the
+// token was already rotated and sql.ErrNoRows when it is unknown, revoked or
+// expired.
+func (s *libsqlDB) RotateRefreshToken(ctx context.Context, tokenID string) error {
 	query := `
-	SELECT COUNT(*) 
-	FROM refresh_tokens 
-	WHERE token_id = ? AND expires_at > ?`
+	UPDATE refresh_tokens
+	SET rotated_at = unixepoch()
+	WHERE token_id = ? AND rotated_at IS NULL AND expires_at > ?`
 
-	var count int
+	res, err := s.db.ExecContext(ctx, query, tokenID, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
 
-	if err := s.db.QueryRow(query, tokenID, time.Now().Unix()).Scan(&count); err != nil {
-		slog.Error("failed to validate refresh token", "error", err, "token_id", tokenID)
-		return false
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if n > 0 {
+		return nil
 	}
 
-	return count > 0
+	var rotated bool
+	if err := s.db.QueryRowContext(ctx, `SELECT rotated_at IS NOT NULL FROM refresh_tokens WHERE token_id = ?`, tokenID).Scan(&rotated); err != nil {
+		return fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+
+	if rotated {
+		return ErrRefreshTokenReused
+	}
+
+	return fmt.Errorf("failed to rotate refresh token: %w", sql.ErrNoRows)
 }
 
-func (s *libsqlDB) RevokeRefreshToken(userID string, tokenID string) error {
+// RevokeRefreshTokenFamily deletes every token of a family, ending the
+// session it belongs to.
+func (s *libsqlDB) RevokeRefreshTokenFamily(ctx context.Context, userID string, familyID string) error {
 	query := `
 	DELETE FROM refresh_tokens 
-	WHERE user_id = ? AND token_id = ?`
+	WHERE user_id = ? AND family_id = ?`
 
-	_, err := s.db.Exec(query, userID, tokenID)
+	_, err := s.db.ExecContext(ctx, query, userID, familyID)
 	if err != nil {
-		return fmt.Errorf("failed to revoke refresh token: %w", err)
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
 	}
 
 	return nil
@@ -103,15 +135,102 @@ func (s *libsqlDB) RevokeAllRefreshTokens(userID string) error {
 	return nil
 }
 
+// ListSessions returns the user's active sessions, one per token family,
+// most recently used first.
+func (s *libsqlDB) ListSessions(ctx context.Context, userID string) ([]models.Session, error) {
+	query := `
+	SELECT family_id, COALESCE(created_at, 0), COALESCE(last_used_at, 0), COALESCE(user_agent, ''), COALESCE(ip, ''), CAST(expires_at AS INTEGER)
+	FROM refresh_tokens
+	WHERE user_id = ? AND family_id IS NOT NULL AND rotated_at IS NULL AND expires_at > ?
+	ORDER BY last_used_at DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, userID, time.Now().Unix())
+	if err != nil {
+		return nil, fmt.Errorf("listing sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]models.Session, 0)
+	for rows.Next() {
+		var session models.Session
+		if err := rows.Scan(&session.ID, &session.CreatedAt, &session.LastUsedAt, &session.UserAgent, &session.IP, &session.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("scanning session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// RevokeAccessToken denies an access token id or a token family until
+// expiresAt, after which every token it covers has expired anyway.
+func (s *libsqlDB) RevokeAccessToken(ctx context.Context, id string, expiresAt int64) error {
+	query := `
+	INSERT INTO revoked_tokens (id, expires_at)
+	VALUES (?, ?)
+	ON CONFLICT(id) DO UPDATE SET expires_at = MAX(expires_at, excluded.expires_at)`
+
+	if _, err := s.db.ExecContext(ctx, query, id, expiresAt); err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+
+	return nil
+}
+
+func (s *libsqlDB) ListRevokedAccessTokens(ctx context.Context) (map[string]int64, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, expires_at FROM revoked_tokens WHERE expires_at > ?`, time.Now().Unix())
+	if err != nil {
+		return nil, fmt.Errorf("listing revoked tokens: %w", err)
+	}
+	defer rows.Close()
+
+	revoked := make(map[string]int64)
+	for rows.Next() {
+		var (
+			id        string
+			expiresAt int64
+		)
+
+		if err := rows.Scan(&id, &expiresAt); err != nil {
+			return nil, fmt.Errorf("scanning revoked token: %w", err)
+		}
+		revoked[id] = expiresAt
+	}
+
+	return revoked, rows.Err()
+}
+
+func (s *libsqlDB) PurgeRevokedAccessTokens(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at <= ?`, time.Now().Unix()); err != nil {
+		return fmt.Errorf("failed to purge revoked tokens: %w", err)
+	}
+
+	return nil
+}
+
 func (s *libsqlDB) CleanupRefreshTokens() error {
 	query := `
 	DELETE FROM refresh_tokens 
 	WHERE expires_at < ?`
 
-	_, err := s.db.Exec(query, time.Now())
+	_, err := s.db.Exec(query, time.Now().Unix())
 	if err != nil {
 		return fmt.Errorf("failed to cleanup refresh tokens: %w", err)
 	}
 
 	return nil
 }
+
+func (s *libsqlDB) RetrieveUserRole(ctx context.Context, userID string) (string, error) {
+	query := `
+	SELECT COALESCE(role, 'user')
+	FROM users
+	WHERE id = ?`
+
+	var role string
+	if err := s.db.QueryRowContext(ctx, query, userID).Scan(&role); err != nil {
+		return "", fmt.Errorf("failed to retrieve user role: %w", err)
+	}
+
+	return role, nil
+}
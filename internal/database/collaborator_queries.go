@@ -0,0 +1,178 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+const collaboratorColumns = `c.strategy_id, s.name, c.user_id, c.username, c.role, c.status, c.invited_by, c.created_at`
+
+func scanCollaborator(row scanner) (*models.Collaborator, error) {
+	var c models.Collaborator
+	if err := row.Scan(&c.StrategyID, &c.StrategyName, &c.UserID, &c.Username, &c.Role, &c.Status, &c.InvitedBy, &c.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// ErrAmbiguousUsername is returned when several users share the account
+// name a collaborator is invited by.
+var ErrAmbiguousUsername = errors.New("ambiguous username")
+
+// InviteCollaborator creates a pending invitation for the user with the given
+// account name, or updates the role of an existing one. It returns
+// sql.ErrNoRows when no such user exists and ErrAmbiguousUsername when the
+// name does not identify a single user.
+func (s *libsqlDB) InviteCollaborator(ctx context.Context, strategyID int, username string, role string, invitedBy string) (*models.Collaborator, error) {
+	userID, err := s.userIDByName(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to invite collaborator: %w", err)
+	}
+
+	query := `
+	INSERT INTO strategy_collaborators (strategy_id, user_id, username, role, invited_by)
+	VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT (strategy_id, user_id) DO UPDATE SET role = excluded.role`
+
+	if _, err := s.db.ExecContext(ctx, query, strategyID, userID, username, role, invitedBy); err != nil {
+		return nil, fmt.Errorf("failed to invite collaborator: %w", err)
+	}
+
+	selectQuery := `
+	SELECT ` + collaboratorColumns + `
+	FROM strategy_collaborators c
+	JOIN strategies s ON s.id = c.strategy_id
+	WHERE c.strategy_id = ? AND c.user_id = ?`
+
+	c, err := scanCollaborator(s.db.QueryRowContext(ctx, selectQuery, strategyID, userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve collaborator: %w", err)
+	}
+
+	return c, nil
+}
+
+// userIDByName returns the id of the only user with the account name.
+func (s *libsqlDB) userIDByName(ctx context.Context, username string) (string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id FROM users WHERE username = ? LIMIT 2`, username)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve user: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0, 2)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return "", fmt.Errorf("failed to scan user: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("failed to retrieve user: %w", err)
+	}
+
+	switch len(ids) {
+	case 0:
+		return "", sql.ErrNoRows
+	case 1:
+		return ids[0], nil
+	default:
+		return "", ErrAmbiguousUsername
+	}
+}
+
+func (s *libsqlDB) ListCollaborators(ctx context.Context, strategyID int) ([]models.Collaborator, error) {
+	query := `
+	SELECT ` + collaboratorColumns + `
+	FROM strategy_collaborators c
+	JOIN strategies s ON s.id = c.strategy_id
+	WHERE c.strategy_id = ?
+	ORDER BY c.created_at`
+
+	return s.listCollaborators(ctx, query, strategyID)
+}
+
+// ListInvitations returns the collaborations of a user with the given status.
+func (s *libsqlDB) ListInvitations(ctx context.Context, userID string, status string) ([]models.Collaborator, error) {
+	query := `
+	SELECT ` + collaboratorColumns + `
+	FROM strategy_collaborators c
+	JOIN strategies s ON s.id = c.strategy_id
+	WHERE c.user_id = ? AND c.status = ? AND s.deleted_at IS NULL
+	ORDER BY c.created_at DESC`
+
+	return s.listCollaborators(ctx, query, userID, status)
+}
+
+func (s *libsqlDB) listCollaborators(ctx context.Context, query string, args ...any) ([]models.Collaborator, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing collaborators: %w", err)
+	}
+	defer rows.Close()
+
+	collaborators := make([]models.Collaborator, 0)
+	for rows.Next() {
+		c, err := scanCollaborator(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning collaborator: %w", err)
+		}
+		collaborators = append(collaborators, *c)
+	}
+
+	return collaborators, rows.Err()
+}
+
+func (s *libsqlDB) AcceptInvitation(ctx context.Context, strategyID int, userID string) error {
+	query := `
+	UPDATE strategy_collaborators
+	SET status = 'accepted'
+	WHERE strategy_id = ? AND user_id = ? AND status = 'pending'`
+
+	res, err := s.db.ExecContext(ctx, query, strategyID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to accept invitation: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("failed to accept invitation: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// RemoveCollaborator deletes an invitation or collaboration by account name.
+func (s *libsqlDB) RemoveCollaborator(ctx context.Context, strategyID int, username string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM strategy_collaborators WHERE strategy_id = ? AND username = ?`, strategyID, username)
+	if err != nil {
+		return fmt.Errorf("failed to remove collaborator: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("failed to remove collaborator: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// RetrieveCollaboratorRole returns the role of an accepted collaborator.
+func (s *libsqlDB) RetrieveCollaboratorRole(ctx context.Context, strategyID int, userID string) (string, error) {
+	query := `
+	SELECT role
+	FROM strategy_collaborators
+	WHERE strategy_id = ? AND user_id = ? AND status = 'accepted'`
+
+	var role string
+	if err := s.db.QueryRowContext(ctx, query, strategyID, userID).Scan(&role); err != nil {
+		return "", fmt.Errorf("failed to retrieve collaborator role: %w", err)
+	}
+
+	return role, nil
+}
@@ -0,0 +1,253 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+const commentColumns = `id, strategy_id, user_id, author, parent_id, root_id, body, deleted_at IS NOT NULL, COALESCE(deleted_by, ''), created_at, updated_at`
+
+func scanComment(row scanner) (*models.Comment, error) {
+	var c models.Comment
+	if err := row.Scan(&c.ID, &c.StrategyID, &c.UserID, &c.Author, &c.ParentID, &c.RootID, &c.Body, &c.Deleted, &c.DeletedBy, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// StoreComment adds a comment to a strategy. Replies inherit the thread root
+// of their parent, which must belong to the same strategy.
+func (s *libsqlDB) StoreComment(ctx context.Context, comment models.Comment) (*models.Comment, error) {
+	query := `
+	INSERT INTO strategy_comments (strategy_id, user_id, author, parent_id, root_id, body)
+	VALUES (?, ?, ?, NULL, NULL, ?)
+	RETURNING ` + commentColumns
+
+	args := []any{comment.StrategyID, comment.UserID, comment.Author, comment.Body}
+
+	if comment.ParentID != nil {
+		query = `
+		INSERT INTO strategy_comments (strategy_id, user_id, author, parent_id, root_id, body)
+		SELECT ?, ?, ?, id, COALESCE(root_id, id), ?
+		FROM strategy_comments
+		WHERE id = ? AND strategy_id = ?
+		RETURNING ` + commentColumns
+
+		args = append(args, *comment.ParentID, comment.StrategyID)
+	}
+
+	stored, err := scanComment(s.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to store comment: %w", err)
+	}
+
+	return stored, nil
+}
+
+func (s *libsqlDB) RetrieveComment(ctx context.Context, strategyID int, commentID int) (*models.Comment, error) {
+	query := `
+	SELECT ` + commentColumns + `
+	FROM strategy_comments
+	WHERE id = ? AND strategy_id = ?`
+
+	comment, err := scanComment(s.db.QueryRowContext(ctx, query, commentID, strategyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve comment: %w", err)
+	}
+
+	return comment, nil
+}
+
+func (s *libsqlDB) UpdateComment(ctx context.Context, commentID int, body string) (*models.Comment, error) {
+	query := `
+	UPDATE strategy_comments
+	SET body = ?, updated_at = unixepoch()
+	WHERE id = ? AND deleted_at IS NULL
+	RETURNING ` + commentColumns
+
+	comment, err := scanComment(s.db.QueryRowContext(ctx, query, body, commentID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update comment: %w", err)
+	}
+
+	return comment, nil
+}
+
+// DeleteComment soft-deletes a comment so replies stay attached to the thread.
+func (s *libsqlDB) DeleteComment(ctx context.Context, commentID int, deletedBy string) error {
+	query := `
+	UPDATE strategy_comments
+	SET deleted_at = unixepoch(), deleted_by = ?
+	WHERE id = ? AND deleted_at IS NULL`
+
+	res, err := s.db.ExecContext(ctx, query, deletedBy, commentID)
+	if err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("failed to delete comment: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+func (s *libsqlDB) RestoreComment(ctx context.Context, commentID int) error {
+	query := `
+	UPDATE strategy_comments
+	SET deleted_at = NULL, deleted_by = NULL
+	WHERE id = ? AND deleted_at IS NOT NULL`
+
+	res, err := s.db.ExecContext(ctx, query, commentID)
+	if err != nil {
+		return fmt.Errorf("failed to restore comment: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("failed to restore comment: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// ListComments paginates the top-level comments of a strategy, newest first,
+// and attaches every reply of those threads in chronological order.
+func (s *libsqlDB) ListComments(ctx context.Context, strategyID int, limit int, offset int) ([]models.Comment, int, error) {
+	var total int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM strategy_comments WHERE strategy_id = ? AND parent_id IS NULL`, strategyID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("counting comments: %w", err)
+	}
+
+	query := `
+	SELECT ` + commentColumns + `
+	FROM strategy_comments
+	WHERE strategy_id = ? AND parent_id IS NULL
+	ORDER BY created_at DESC, id DESC
+	LIMIT ?
+	OFFSET ?`
+
+	rows, err := s.db.QueryContext(ctx, query, strategyID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("listing comments: %w", err)
+	}
+	defer rows.Close()
+
+	roots := make([]models.Comment, 0)
+	for rows.Next() {
+		c, err := scanComment(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scanning comment: %w", err)
+		}
+		roots = append(roots, *c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	if len(roots) == 0 {
+		return roots, total, nil
+	}
+
+	args := make([]any, 0, len(roots)+1)
+	args = append(args, strategyID)
+	for _, c := range roots {
+		args = append(args, c.ID)
+	}
+
+	repliesQuery := fmt.Sprintf(`
+	SELECT %s
+	FROM strategy_comments
+	WHERE strategy_id = ? AND root_id IN (%s)
+	ORDER BY created_at, id`, commentColumns, strings.TrimSuffix(strings.Repeat("?, ", len(roots)), ", "))
+
+	replyRows, err := s.db.QueryContext(ctx, repliesQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("listing replies: %w", err)
+	}
+	defer replyRows.Close()
+
+	replies := make([]models.Comment, 0)
+	for replyRows.Next() {
+		c, err := scanComment(replyRows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scanning reply: %w", err)
+		}
+		replies = append(replies, *c)
+	}
+
+	return threadComments(roots, replies), total, replyRows.Err()
+}
+
+// threadComments nests replies under their parents. Soft-deleted comments
+// keep their place in the thread but not their content.
+func threadComments(roots []models.Comment, replies []models.Comment) []models.Comment {
+	children := make(map[int][]models.Comment)
+	for _, c := range replies {
+		children[*c.ParentID] = append(children[*c.ParentID], c)
+	}
+
+	var attach func(c *models.Comment)
+	attach = func(c *models.Comment) {
+		if c.Deleted {
+			c.Body = ""
+		}
+
+		c.Replies = children[c.ID]
+		for i := range c.Replies {
+			attach(&c.Replies[i])
+		}
+	}
+
+	for i := range roots {
+		attach(&roots[i])
+	}
+
+	return roots
+}
+
+// ListRecentComments lists comments across all strategies for moderation.
+func (s *libsqlDB) ListRecentComments(ctx context.Context, deleted bool, limit int, offset int) ([]models.Comment, int, error) {
+	where := "deleted_at IS NULL"
+	if deleted {
+		where = "deleted_at IS NOT NULL"
+	}
+
+	// comments of strategies in the trash are hidden with their strategy
+	where += " AND strategy_id IN (SELECT id FROM strategies WHERE deleted_at IS NULL)"
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM strategy_comments WHERE `+where).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("counting comments: %w", err)
+	}
+
+	query := `
+	SELECT ` + commentColumns + `
+	FROM strategy_comments
+	WHERE ` + where + `
+	ORDER BY created_at DESC, id DESC
+	LIMIT ?
+	OFFSET ?`
+
+	rows, err := s.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("listing comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := make([]models.Comment, 0)
+	for rows.Next() {
+		c, err := scanComment(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scanning comment: %w", err)
+		}
+		comments = append(comments, *c)
+	}
+
+	return comments, total, rows.Err()
+}
@@ -29,16 +29,96 @@ type Service interface {
 	StoreOAuthToken(id string, token models.OAuthToken) error
 	RemoveOAuthToken(id string) error
 
-	StoreRefreshToken(userID string, tokenID string, expiration time.Duration) error
-	IsRefreshTokenValid(tokenID string) bool
-	RevokeRefreshToken(userID string, tokenID string) error
+	StoreRefreshToken(userID string, tokenID string, familyID string, expiration time.Duration, client models.SessionClient) error
+	RotateRefreshToken(ctx context.Context, tokenID string) error
+	RevokeRefreshTokenFamily(ctx context.Context, userID string, familyID string) error
 	RevokeAllRefreshTokens(userID string) error
-
-	StoreStrategy(user models.UserProfile, strategy models.Strategy) (*models.StrategyDTO, error)
-	StoreStrategyTable(strategyID string, table models.StrategyTable) (*models.StrategyTable, error)
-	StoreStrategyItem(strategyID string, item models.StrategyItem) error
-
-	RetrieveStrategy(id string) (*models.Strategy, error)
+	ListSessions(ctx context.Context, userID string) ([]models.Session, error)
+	RevokeAccessToken(ctx context.Context, id string, expiresAt int64) error
+	ListRevokedAccessTokens(ctx context.Context) (map[string]int64, error)
+	PurgeRevokedAccessTokens(ctx context.Context) error
+
+	RetrieveUserRole(ctx context.Context, userID string) (string, error)
+
+	StoreStrategy(ctx context.Context, user models.UserProfile, strategy models.Strategy) (*models.StrategyDTO, error)
+	RetrieveStrategy(ctx context.Context, id int) (*models.Strategy, error)
+	UpdateStrategy(ctx context.Context, strategy models.Strategy, createdBy string) (*models.Strategy, error)
+	DeleteStrategy(ctx context.Context, id int, version int) error
+	ListTrash(ctx context.Context, userID string, limit int, offset int) ([]models.StrategyDTO, int, error)
+	RestoreStrategy(ctx context.Context, userID string, id int) (*models.Strategy, error)
+	PurgeStrategy(ctx context.Context, userID string, id int) error
+	PurgeDeletedStrategies(ctx context.Context, before int64) (int, error)
+	ListStrategies(ctx context.Context, q models.StrategyQuery) ([]models.StrategyDTO, int, error)
+	RetrieveStrategyTags(ctx context.Context, strategyID int) ([]models.Tag, error)
+	SetStrategyTags(ctx context.Context, strategyID int, tags []models.Tag, version int) (int, error)
+	ListTagSuggestions(ctx context.Context, prefix string, category string, limit int) ([]models.TagSuggestion, error)
+	ForkStrategy(ctx context.Context, strategyID int, user models.UserProfile) (*models.Strategy, error)
+	CreateStrategyWithTables(ctx context.Context, user models.UserProfile, strategy models.Strategy, tables []models.SnapshotTable) (*models.Strategy, error)
+	RetrieveStrategySnapshot(ctx context.Context, strategyID int) (*models.StrategySnapshot, error)
+
+	StoreTemplate(ctx context.Context, template models.StrategyTemplate) (*models.StrategyTemplate, error)
+	RetrieveTemplate(ctx context.Context, id int) (*models.StrategyTemplate, error)
+	ListTemplates(ctx context.Context) ([]models.StrategyTemplate, error)
+	UpdateTemplate(ctx context.Context, template models.StrategyTemplate) (*models.StrategyTemplate, error)
+	DeleteTemplate(ctx context.Context, id int) error
+	InstantiateTemplate(ctx context.Context, templateID int, user models.UserProfile) (*models.Strategy, error)
+
+	RetrieveItemRefs(ctx context.Context, itemIDs []string) (map[string]models.ItemRef, error)
+	ResolveItemRef(ctx context.Context, ref models.ItemRef) (string, error)
+
+	LikeStrategy(ctx context.Context, strategyID int, userID string) (bool, error)
+	UnlikeStrategy(ctx context.Context, strategyID int, userID string) error
+	IsStrategyLiked(ctx context.Context, strategyID int, userID string) (bool, error)
+	RecordStrategyView(ctx context.Context, strategyID int, viewer string) error
+
+	InviteCollaborator(ctx context.Context, strategyID int, username string, role string, invitedBy string) (*models.Collaborator, error)
+	ListCollaborators(ctx context.Context, strategyID int) ([]models.Collaborator, error)
+	ListInvitations(ctx context.Context, userID string, status string) ([]models.Collaborator, error)
+	AcceptInvitation(ctx context.Context, strategyID int, userID string) error
+	RemoveCollaborator(ctx context.Context, strategyID int, username string) error
+	RetrieveCollaboratorRole(ctx context.Context, strategyID int, userID string) (string, error)
+
+	StoreRun(ctx context.Context, run models.StrategyRun) (*models.StrategyRun, error)
+	RetrieveRun(ctx context.Context, strategyID int, runID int) (*models.StrategyRun, error)
+	ListRuns(ctx context.Context, strategyID int, limit int, offset int) ([]models.StrategyRun, int, error)
+	DeleteRun(ctx context.Context, strategyID int, runID int) error
+	RetrieveRunTotals(ctx context.Context, strategyID int, league string) (*models.RunTotals, error)
+
+	StoreShareLink(ctx context.Context, strategyID int, token string, expiresAt *int64) (*models.ShareLink, error)
+	ListShareLinks(ctx context.Context, strategyID int) ([]models.ShareLink, error)
+	RevokeShareLink(ctx context.Context, strategyID int, linkID int) error
+	IsShareTokenValid(ctx context.Context, strategyID int, token string) (bool, error)
+
+	StoreComment(ctx context.Context, comment models.Comment) (*models.Comment, error)
+	RetrieveComment(ctx context.Context, strategyID int, commentID int) (*models.Comment, error)
+	UpdateComment(ctx context.Context, commentID int, body string) (*models.Comment, error)
+	DeleteComment(ctx context.Context, commentID int, deletedBy string) error
+	RestoreComment(ctx context.Context, commentID int) error
+	ListComments(ctx context.Context, strategyID int, limit int, offset int) ([]models.Comment, int, error)
+	ListRecentComments(ctx context.Context, deleted bool, limit int, offset int) ([]models.Comment, int, error)
+
+	FeatureStrategy(ctx context.Context, strategyID int, req models.FeatureRequest) error
+	UnfeatureStrategy(ctx context.Context, strategyID int) error
+	ListFeaturedStrategies(ctx context.Context) ([]models.FeaturedStrategy, error)
+
+	StoreStrategyTable(ctx context.Context, strategyID int, table models.StrategyTable, createdBy string) (*models.StrategyTable, error)
+	RetrieveStrategyTables(ctx context.Context, strategyID int) ([]models.StrategyTable, error)
+	UpdateStrategyTable(ctx context.Context, table models.StrategyTable, createdBy string) (*models.StrategyTable, error)
+	DeleteStrategyTable(ctx context.Context, strategyID int, tableID int, version int, createdBy string) error
+
+	StoreStrategyItem(ctx context.Context, strategyID int, item models.StrategyItem, createdBy string) (*models.StrategyItem, error)
+	RetrieveStrategyItems(ctx context.Context, strategyID int, tableID int) ([]models.StrategyItem, error)
+	ExistingItemIDs(ctx context.Context, ids []string) (map[string]bool, error)
+	UpdateStrategyItem(ctx context.Context, item models.StrategyItem, createdBy string) (*models.StrategyItem, error)
+	DeleteStrategyItem(ctx context.Context, strategyID int, tableID int, itemID int, version int, createdBy string) error
+
+	ListStrategyRevisions(ctx context.Context, strategyID int) ([]models.StrategyRevision, error)
+	RetrieveStrategyRevision(ctx context.Context, strategyID int, revision int) (*models.StrategyRevision, error)
+	RestoreStrategyRevision(ctx context.Context, strategyID int, revision int, version int, createdBy string) (*models.StrategyRevision, int, error)
+
+	RetrievePricedStrategyItems(ctx context.Context, strategyID int, league string) ([]models.PricedStrategyItem, error)
+	RetrievePriceHistory(ctx context.Context, itemIDs []string, league string, from int64, to int64) ([]models.PricePoint, error)
+	ListRankableStrategies(ctx context.Context) ([]models.Strategy, error)
 
 	Close() error
 }
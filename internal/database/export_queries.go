@@ -0,0 +1,118 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+func (s *libsqlDB) RetrieveStrategySnapshot(ctx context.Context, strategyID int) (*models.StrategySnapshot, error) {
+	snapshot, err := retrieveSnapshot(ctx, s.db, strategyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve snapshot: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// RetrieveItemRefs maps item ids to their stable references.
+func (s *libsqlDB) RetrieveItemRefs(ctx context.Context, itemIDs []string) (map[string]models.ItemRef, error) {
+	refs := make(map[string]models.ItemRef, len(itemIDs))
+	if len(itemIDs) == 0 {
+		return refs, nil
+	}
+
+	args := make([]any, 0, len(itemIDs))
+	for _, id := range itemIDs {
+		args = append(args, id)
+	}
+
+	query := fmt.Sprintf(`
+	SELECT id, COALESCE(name, ''), COALESCE(base_type, ''), COALESCE(realm, '')
+	FROM items
+	WHERE id IN (%s)`, strings.TrimSuffix(strings.Repeat("?, ", len(itemIDs)), ", "))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving item refs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id  string
+			ref models.ItemRef
+		)
+
+		if err := rows.Scan(&id, &ref.Name, &ref.BaseType, &ref.Realm); err != nil {
+			return nil, fmt.Errorf("scanning item ref: %w", err)
+		}
+		refs[id] = ref
+	}
+
+	return refs, rows.Err()
+}
+
+// ResolveItemRef finds the id of the item matching the reference. The realm
+// only narrows the match when it is set.
+func (s *libsqlDB) ResolveItemRef(ctx context.Context, ref models.ItemRef) (string, error) {
+	query := `
+	SELECT id
+	FROM items
+	WHERE name = ? AND base_type = ? AND (? = '' OR realm = ?)
+	ORDER BY id
+	LIMIT 1`
+
+	var id string
+	if err := s.db.QueryRowContext(ctx, query, ref.Name, ref.BaseType, ref.Realm, ref.Realm).Scan(&id); err != nil {
+		return "", fmt.Errorf("failed to resolve item %q: %w", ref.BaseType, err)
+	}
+
+	return id, nil
+}
+
+// CreateStrategyWithTables stores a new strategy together with its tags,
+// tables and items in a single transaction.
+func (s *libsqlDB) CreateStrategyWithTables(ctx context.Context, user models.UserProfile, strategy models.Strategy, tables []models.SnapshotTable) (*models.Strategy, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	created, err := createStrategyWithTables(ctx, tx, user, strategy, tables)
+	if err != nil {
+		return nil, err
+	}
+
+	return created, tx.Commit()
+}
+
+func createStrategyWithTables(ctx context.Context, q querier, user models.UserProfile, strategy models.Strategy, tables []models.SnapshotTable) (*models.Strategy, error) {
+	query := `
+	INSERT INTO strategies (user_id, created_by, name, description, atlas, atlas_tree, duration, league, public)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	RETURNING ` + strategyColumns
+
+	created, err := scanStrategy(q.QueryRowContext(ctx, query, user.ID, user.Name, strategy.Name, strategy.Description, strategy.Atlas, strategy.AtlasTree, strategy.Duration, strategy.League, strategy.Public))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create strategy: %w", err)
+	}
+
+	if err := insertSnapshotTables(ctx, q, created.ID, tables); err != nil {
+		return nil, fmt.Errorf("failed to create strategy: %w", err)
+	}
+
+	if err := insertStrategyTags(ctx, q, created.ID, strategy.Tags); err != nil {
+		return nil, fmt.Errorf("failed to create strategy: %w", err)
+	}
+	created.Tags = strategy.Tags
+
+	if _, err := createRevision(ctx, q, created.ID, user.Name); err != nil {
+		return nil, fmt.Errorf("failed to create strategy: %w", err)
+	}
+
+	return created, nil
+}
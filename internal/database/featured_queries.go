@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+// FeatureStrategy features a public strategy. Private and trashed strategies
+// cannot be featured and return sql.ErrNoRows.
+func (s *libsqlDB) FeatureStrategy(ctx context.Context, strategyID int, req models.FeatureRequest) error {
+	query := `
+	UPDATE strategies
+	SET
+		featured = 1,
+		featured_position = ?,
+		featured_until = ?
+	WHERE id = ? AND deleted_at IS NULL AND public = 1`
+
+	res, err := s.db.ExecContext(ctx, query, req.Position, req.ExpiresAt, strategyID)
+	if err != nil {
+		return fmt.Errorf("failed to feature strategy: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("failed to feature strategy: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+func (s *libsqlDB) UnfeatureStrategy(ctx context.Context, strategyID int) error {
+	query := `
+	UPDATE strategies
+	SET
+		featured = 0,
+		featured_position = 0,
+		featured_until = NULL
+	WHERE id = ?`
+
+	res, err := s.db.ExecContext(ctx, query, strategyID)
+	if err != nil {
+		return fmt.Errorf("failed to unfeature strategy: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("failed to unfeature strategy: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// ListFeaturedStrategies returns public featured strategies that have not
+// expired, ordered by their curated position.
+func (s *libsqlDB) ListFeaturedStrategies(ctx context.Context) ([]models.FeaturedStrategy, error) {
+	query := `
+	SELECT ` + strategyColumns + `, featured_position, featured_until
+	FROM strategies
+	WHERE
+		featured = 1
+		AND public = 1
+		AND deleted_at IS NULL
+		AND (featured_until IS NULL OR featured_until > unixepoch())
+	ORDER BY featured_position, id`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("listing featured strategies: %w", err)
+	}
+	defer rows.Close()
+
+	featured := make([]models.FeaturedStrategy, 0)
+
+	for rows.Next() {
+		var (
+			st models.Strategy
+			fs models.FeaturedStrategy
+		)
+
+		if err := rows.Scan(append(strategyFields(&st), &fs.Position, &fs.ExpiresAt)...); err != nil {
+			return nil, fmt.Errorf("scanning featured strategy: %w", err)
+		}
+
+		fs.StrategyDTO = st.DTO()
+		featured = append(featured, fs)
+	}
+
+	return featured, rows.Err()
+}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+// ForkStrategy deep-copies a strategy with its tables and items into the
+// user's account and bumps the fork count of the original. Everything runs in
+// a single transaction so a fork is never left half-copied.
+func (s *libsqlDB) ForkStrategy(ctx context.Context, strategyID int, user models.UserProfile) (*models.Strategy, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	snapshot, err := retrieveSnapshot(ctx, tx, strategyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fork strategy: %w", err)
+	}
+
+	query := `
+	INSERT INTO strategies (user_id, created_by, name, description, atlas, atlas_tree, duration, league, public, forked_from, forked_from_by)
+	SELECT ?, ?, name, description, atlas, atlas_tree, duration, league, 0, id, created_by
+	FROM strategies
+	WHERE id = ? AND deleted_at IS NULL
+	RETURNING ` + strategyColumns
+
+	fork, err := scanStrategy(tx.QueryRowContext(ctx, query, user.ID, user.Name, strategyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fork strategy: %w", err)
+	}
+
+	if err := insertSnapshotTables(ctx, tx, fork.ID, snapshot.Tables); err != nil {
+		return nil, fmt.Errorf("failed to fork strategy: %w", err)
+	}
+
+	query = `
+	INSERT INTO strategy_tags (strategy_id, tag, category)
+	SELECT ?, tag, category
+	FROM strategy_tags
+	WHERE strategy_id = ?`
+
+	if _, err := tx.ExecContext(ctx, query, fork.ID, strategyID); err != nil {
+		return nil, fmt.Errorf("failed to copy strategy tags: %w", err)
+	}
+
+	query = `
+	UPDATE strategies
+	SET fork_count = fork_count + 1, popularity = popularity + ?
+	WHERE id = ?`
+
+	if _, err := tx.ExecContext(ctx, query, popularityBoost(forkWeight, time.Now()), strategyID); err != nil {
+		return nil, fmt.Errorf("failed to update fork count: %w", err)
+	}
+
+	if _, err := createRevision(ctx, tx, fork.ID, user.Name); err != nil {
+		return nil, fmt.Errorf("failed to fork strategy: %w", err)
+	}
+
+	return fork, tx.Commit()
+}
@@ -0,0 +1,35 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+// ListRankableStrategies returns the public strategies that declare a run
+// duration, which is required to compute their profit per hour.
+func (s *libsqlDB) ListRankableStrategies(ctx context.Context) ([]models.Strategy, error) {
+	query := `
+	SELECT ` + strategyColumns + `
+	FROM strategies
+	WHERE public = 1 AND duration > 0 AND deleted_at IS NULL
+	ORDER BY id`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("listing rankable strategies: %w", err)
+	}
+	defer rows.Close()
+
+	strategies := make([]models.Strategy, 0)
+	for rows.Next() {
+		st, err := scanStrategy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning strategy: %w", err)
+		}
+		strategies = append(strategies, *st)
+	}
+
+	return strategies, rows.Err()
+}
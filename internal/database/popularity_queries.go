@@ -0,0 +1,133 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Popularity decays exponentially with a fixed half-life. Instead of decaying
+// every stored score over time, each event is weighted by how far it lies
+// past a fixed epoch. All scores share the same decay factor at any given
+// moment, so ordering by the stored value equals ordering by the decayed one.
+const (
+	popularityHalfLife = 7 * 24 * time.Hour
+
+	viewWeight = 1
+	likeWeight = 5
+	forkWeight = 10
+)
+
+var popularityEpoch = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func popularityBoost(weight float64, at time.Time) float64 {
+	return weight * math.Exp2(at.Sub(popularityEpoch).Hours()/popularityHalfLife.Hours())
+}
+
+// LikeStrategy records the user's like once and reports whether it was new.
+func (s *libsqlDB) LikeStrategy(ctx context.Context, strategyID int, userID string) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	res, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO strategy_likes (strategy_id, user_id, created_at) VALUES (?, ?, ?)`, strategyID, userID, now.Unix())
+	if err != nil {
+		return false, fmt.Errorf("failed to like strategy: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+
+	query := `
+	UPDATE strategies
+	SET like_count = like_count + 1, popularity = popularity + ?
+	WHERE id = ?`
+
+	if _, err := tx.ExecContext(ctx, query, popularityBoost(likeWeight, now), strategyID); err != nil {
+		return false, fmt.Errorf("failed to update like count: %w", err)
+	}
+
+	return true, tx.Commit()
+}
+
+// UnlikeStrategy removes the user's like together with the popularity it
+// contributed at the time it was given.
+func (s *libsqlDB) UnlikeStrategy(ctx context.Context, strategyID int, userID string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var likedAt int64
+
+	err = tx.QueryRowContext(ctx, `DELETE FROM strategy_likes WHERE strategy_id = ? AND user_id = ? RETURNING created_at`, strategyID, userID).Scan(&likedAt)
+	if err != nil {
+		return fmt.Errorf("failed to unlike strategy: %w", err)
+	}
+
+	query := `
+	UPDATE strategies
+	SET like_count = MAX(like_count - 1, 0), popularity = MAX(popularity - ?, 0)
+	WHERE id = ?`
+
+	if _, err := tx.ExecContext(ctx, query, popularityBoost(likeWeight, time.Unix(likedAt, 0)), strategyID); err != nil {
+		return fmt.Errorf("failed to update like count: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (s *libsqlDB) IsStrategyLiked(ctx context.Context, strategyID int, userID string) (bool, error) {
+	var liked int
+
+	err := s.db.QueryRowContext(ctx, `SELECT 1 FROM strategy_likes WHERE strategy_id = ? AND user_id = ?`, strategyID, userID).Scan(&liked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check strategy like: %w", err)
+	}
+
+	return true, nil
+}
+
+// RecordStrategyView counts a view once per viewer, where viewer is either a
+// user id or an anonymous session id.
+func (s *libsqlDB) RecordStrategyView(ctx context.Context, strategyID int, viewer string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	res, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO strategy_views (strategy_id, viewer, viewed_at) VALUES (?, ?, ?)`, strategyID, viewer, now.Unix())
+	if err != nil {
+		return fmt.Errorf("failed to record view: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+
+	query := `
+	UPDATE strategies
+	SET view_count = view_count + 1, popularity = popularity + ?
+	WHERE id = ?`
+
+	if _, err := tx.ExecContext(ctx, query, popularityBoost(viewWeight, now), strategyID); err != nil {
+		return fmt.Errorf("failed to update view count: %w", err)
+	}
+
+	return tx.Commit()
+}
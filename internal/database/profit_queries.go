@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+// RetrievePricedStrategyItems returns every item of the strategy priced by
+// its override, or else by its current value in the given league. Items
+// without either are returned with Priced set to false.
+func (s *libsqlDB) RetrievePricedStrategyItems(ctx context.Context, strategyID int, league string) ([]models.PricedStrategyItem, error) {
+	query := fmt.Sprintf(`
+	SELECT
+		si.id,
+		si.strategy_id,
+		si.table_id,
+		si.item_id,
+		si.amount,
+		si.role,
+		si.drop_chance,
+		si.pair,
+		si.price_override,
+		COALESCE(st.type, ''),
+		COALESCE(fi.name, ''),
+		COALESCE(fi.base_type, ''),
+		COALESCE(fi.icon, ''),
+		fi.%s_value
+	FROM strategy_items si
+	LEFT JOIN strategy_tables st ON st.id = si.table_id
+	LEFT JOIN full_items fi ON fi.id = si.item_id
+	WHERE si.strategy_id = ?
+	ORDER BY si.table_id, si.id`, league)
+
+	rows, err := s.db.QueryContext(ctx, query, strategyID)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving priced strategy items: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]models.PricedStrategyItem, 0)
+
+	for rows.Next() {
+		var (
+			i     models.PricedStrategyItem
+			price sql.NullFloat64
+		)
+
+		err := rows.Scan(&i.SID, &i.StrategyID, &i.TableID, &i.ItemID, &i.Amount, &i.Role, &i.DropChance, &i.Pair, &i.PriceOverride, &i.TableType, &i.Name, &i.BaseType, &i.Icon, &price)
+		if err != nil {
+			return nil, fmt.Errorf("scanning priced strategy item: %w", err)
+		}
+
+		switch {
+		case i.PriceOverride != nil:
+			i.Price, i.Priced, i.PriceSource = *i.PriceOverride, true, models.PriceSourceOverride
+		case price.Valid:
+			i.Price, i.Priced, i.PriceSource = price.Float64, true, models.PriceSourceMarket
+		default:
+			i.PriceSource = models.PriceSourceNone
+		}
+
+		items = append(items, i)
+	}
+
+	return items, rows.Err()
+}
+
+// RetrievePriceHistory returns the price observations of the given items
+// between from and to, plus the last observation of each item before from
+// so the first point of a series can be valued.
+func (s *libsqlDB) RetrievePriceHistory(ctx context.Context, itemIDs []string, league string, from int64, to int64) ([]models.PricePoint, error) {
+	if len(itemIDs) == 0 {
+		return []models.PricePoint{}, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(itemIDs)), ", ")
+
+	query := fmt.Sprintf(`
+	SELECT item_id, price, COALESCE(currency_id, ''), timestamp
+	FROM prices
+	WHERE league = ? AND item_id IN (%[1]s) AND timestamp BETWEEN ? AND ?
+	UNION ALL
+	SELECT item_id, price, COALESCE(currency_id, ''), MAX(timestamp)
+	FROM prices
+	WHERE league = ? AND item_id IN (%[1]s) AND timestamp < ?
+	GROUP BY item_id`, placeholders)
+
+	args := make([]any, 0, 2*len(itemIDs)+5)
+	args = append(args, league)
+	for _, id := range itemIDs {
+		args = append(args, id)
+	}
+	args = append(args, from, to, league)
+	for _, id := range itemIDs {
+		args = append(args, id)
+	}
+	args = append(args, from)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving price history: %w", err)
+	}
+	defer rows.Close()
+
+	points := make([]models.PricePoint, 0)
+
+	for rows.Next() {
+		var p models.PricePoint
+		if err := rows.Scan(&p.ItemID, &p.Price, &p.CurrencyID, &p.Timestamp); err != nil {
+			return nil, fmt.Errorf("scanning price point: %w", err)
+		}
+		points = append(points, p)
+	}
+
+	return points, rows.Err()
+}
@@ -0,0 +1,341 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+// retrieveSnapshot reads the current content of a strategy.
+func retrieveSnapshot(ctx context.Context, q querier, strategyID int) (*models.StrategySnapshot, error) {
+	var snapshot models.StrategySnapshot
+
+	err := q.QueryRowContext(ctx, `SELECT name, description, atlas, atlas_tree, duration, league FROM strategies WHERE id = ?`, strategyID).Scan(&snapshot.Name, &snapshot.Description, &snapshot.Atlas, &snapshot.AtlasTree, &snapshot.Duration, &snapshot.League)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving strategy: %w", err)
+	}
+
+	tableRows, err := q.QueryContext(ctx, `SELECT `+strategyTableColumns+` FROM strategy_tables WHERE strategy_id = ? ORDER BY id`, strategyID)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving strategy tables: %w", err)
+	}
+	defer tableRows.Close()
+
+	index := make(map[int]int)
+	snapshot.Tables = make([]models.SnapshotTable, 0)
+
+	for tableRows.Next() {
+		st, err := scanStrategyTable(tableRows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning strategy table: %w", err)
+		}
+
+		t := models.SnapshotTable{StrategyTable: *st}
+
+		t.Items = make([]models.StrategyItem, 0)
+		index[t.ID] = len(snapshot.Tables)
+		snapshot.Tables = append(snapshot.Tables, t)
+	}
+
+	if err := tableRows.Err(); err != nil {
+		return nil, err
+	}
+
+	itemRows, err := q.QueryContext(ctx, `SELECT `+strategyItemColumns+` FROM strategy_items WHERE strategy_id = ? ORDER BY id`, strategyID)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving strategy items: %w", err)
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		item, err := scanStrategyItem(itemRows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning strategy item: %w", err)
+		}
+
+		if i, ok := index[item.TableID]; ok {
+			snapshot.Tables[i].Items = append(snapshot.Tables[i].Items, *item)
+		}
+	}
+
+	return &snapshot, itemRows.Err()
+}
+
+// insertSnapshotTables copies tables and their items into a strategy.
+// Rows get new ids, so Pair references are remapped to the copied items.
+func insertSnapshotTables(ctx context.Context, q querier, strategyID int, tables []models.SnapshotTable) error {
+	itemIDs := make(map[int]int)
+	paired := make(map[int]int)
+
+	for _, table := range tables {
+		var tableID int
+		err := q.QueryRowContext(ctx, `INSERT INTO strategy_tables (strategy_id, type, title, scale) VALUES (?, ?, ?, ?) RETURNING id`, strategyID, table.Type, table.Title, table.Scale).Scan(&tableID)
+		if err != nil {
+			return fmt.Errorf("copying strategy table: %w", err)
+		}
+
+		for _, item := range table.Items {
+			query := `
+			INSERT INTO strategy_items (strategy_id, table_id, item_id, amount, role, drop_chance, pair, price_override)
+			VALUES (?, ?, ?, ?, ?, ?, 0, ?)
+			RETURNING id`
+
+			var id int
+			if err := q.QueryRowContext(ctx, query, strategyID, tableID, item.ItemID, item.Amount, item.Role, item.DropChance, item.PriceOverride).Scan(&id); err != nil {
+				return fmt.Errorf("copying strategy item: %w", err)
+			}
+
+			itemIDs[item.SID] = id
+			if item.Pair != 0 {
+				paired[id] = item.Pair
+			}
+		}
+	}
+
+	for id, pair := range paired {
+		if _, err := q.ExecContext(ctx, `UPDATE strategy_items SET pair = ? WHERE id = ?`, itemIDs[pair], id); err != nil {
+			return fmt.Errorf("remapping item pair: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// restoreSnapshotTables makes the tables and items of a strategy match a
+// snapshot in place. Rows that still exist keep their ids, so run history
+// and revision diffs keep referring to them; rows missing from the strategy
+// are inserted and rows missing from the snapshot deleted.
+func restoreSnapshotTables(ctx context.Context, q querier, strategyID int, tables []models.SnapshotTable) error {
+	current, err := retrieveSnapshot(ctx, q, strategyID)
+	if err != nil {
+		return err
+	}
+
+	existingTables := make(map[int]bool)
+	existingItems := make(map[int]bool)
+	for _, table := range current.Tables {
+		existingTables[table.ID] = true
+		for _, item := range table.Items {
+			existingItems[item.SID] = true
+		}
+	}
+
+	keptTables := make([]any, 0, len(tables))
+	keptItems := make([]any, 0)
+	itemIDs := make(map[int]int)
+	paired := make(map[int]int)
+
+	for _, table := range tables {
+		tableID := table.ID
+
+		if existingTables[table.ID] {
+			if _, err := q.ExecContext(ctx, `UPDATE strategy_tables SET type = ?, title = ?, scale = ?, version = version + 1 WHERE id = ?`, table.Type, table.Title, table.Scale, table.ID); err != nil {
+				return fmt.Errorf("restoring strategy table: %w", err)
+			}
+		} else {
+			err := q.QueryRowContext(ctx, `INSERT INTO strategy_tables (strategy_id, type, title, scale) VALUES (?, ?, ?, ?) RETURNING id`, strategyID, table.Type, table.Title, table.Scale).Scan(&tableID)
+			if err != nil {
+				return fmt.Errorf("restoring strategy table: %w", err)
+			}
+		}
+
+		keptTables = append(keptTables, tableID)
+
+		for _, item := range table.Items {
+			id := item.SID
+
+			if existingItems[item.SID] {
+				query := `
+				UPDATE strategy_items
+				SET table_id = ?, item_id = ?, amount = ?, role = ?, drop_chance = ?, price_override = ?, version = version + 1
+				WHERE id = ?`
+
+				if _, err := q.ExecContext(ctx, query, tableID, item.ItemID, item.Amount, item.Role, item.DropChance, item.PriceOverride, item.SID); err != nil {
+					return fmt.Errorf("restoring strategy item: %w", err)
+				}
+			} else {
+				query := `
+				INSERT INTO strategy_items (strategy_id, table_id, item_id, amount, role, drop_chance, pair, price_override)
+				VALUES (?, ?, ?, ?, ?, ?, 0, ?)
+				RETURNING id`
+
+				if err := q.QueryRowContext(ctx, query, strategyID, tableID, item.ItemID, item.Amount, item.Role, item.DropChance, item.PriceOverride).Scan(&id); err != nil {
+					return fmt.Errorf("restoring strategy item: %w", err)
+				}
+			}
+
+			keptItems = append(keptItems, id)
+			itemIDs[item.SID] = id
+			paired[id] = item.Pair
+		}
+	}
+
+	// children first: items of deleted tables are gone either way
+	itemsQuery := fmt.Sprintf(`DELETE FROM strategy_items WHERE strategy_id = ? AND id NOT IN (%s)`, strings.TrimSuffix(strings.Repeat("?, ", len(keptItems)), ", "))
+	if _, err := q.ExecContext(ctx, itemsQuery, append([]any{strategyID}, keptItems...)...); err != nil {
+		return fmt.Errorf("removing strategy items: %w", err)
+	}
+
+	tablesQuery := fmt.Sprintf(`DELETE FROM strategy_tables WHERE strategy_id = ? AND id NOT IN (%s)`, strings.TrimSuffix(strings.Repeat("?, ", len(keptTables)), ", "))
+	if _, err := q.ExecContext(ctx, tablesQuery, append([]any{strategyID}, keptTables...)...); err != nil {
+		return fmt.Errorf("removing strategy tables: %w", err)
+	}
+
+	// pairs refer to items by their id in the snapshot
+	for id, pair := range paired {
+		if _, err := q.ExecContext(ctx, `UPDATE strategy_items SET pair = ? WHERE id = ?`, itemIDs[pair], id); err != nil {
+			return fmt.Errorf("restoring item pair: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func createRevision(ctx context.Context, q querier, strategyID int, createdBy string) (*models.StrategyRevision, error) {
+	snapshot, err := retrieveSnapshot(ctx, q, strategyID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("encoding snapshot: %w", err)
+	}
+
+	query := `
+	INSERT INTO strategy_revisions (strategy_id, revision, created_by, snapshot)
+	SELECT ?, COALESCE(MAX(revision), 0) + 1, ?, ?
+	FROM strategy_revisions
+	WHERE strategy_id = ?
+	RETURNING id, strategy_id, revision, created_by, created_at`
+
+	var rev models.StrategyRevision
+	if err := q.QueryRowContext(ctx, query, strategyID, createdBy, string(data), strategyID).Scan(&rev.ID, &rev.StrategyID, &rev.Revision, &rev.CreatedBy, &rev.CreatedAt); err != nil {
+		return nil, fmt.Errorf("storing revision: %w", err)
+	}
+
+	rev.Snapshot = snapshot
+
+	return &rev, nil
+}
+
+// revise runs a mutation of the strategy and records the resulting content
+// as its next revision in the same transaction, so no committed change is
+// missing from the history.
+func (s *libsqlDB) revise(ctx context.Context, strategyID int, createdBy string, mutate func(q querier) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := mutate(tx); err != nil {
+		return err
+	}
+
+	if _, err := createRevision(ctx, tx, strategyID, createdBy); err != nil {
+		return fmt.Errorf("failed to record revision: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (s *libsqlDB) ListStrategyRevisions(ctx context.Context, strategyID int) ([]models.StrategyRevision, error) {
+	query := `
+	SELECT id, strategy_id, revision, created_by, created_at
+	FROM strategy_revisions
+	WHERE strategy_id = ?
+	ORDER BY revision DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, strategyID)
+	if err != nil {
+		return nil, fmt.Errorf("listing revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := make([]models.StrategyRevision, 0)
+
+	for rows.Next() {
+		var rev models.StrategyRevision
+		if err := rows.Scan(&rev.ID, &rev.StrategyID, &rev.Revision, &rev.CreatedBy, &rev.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning revision: %w", err)
+		}
+		revisions = append(revisions, rev)
+	}
+
+	return revisions, rows.Err()
+}
+
+func retrieveRevision(ctx context.Context, q querier, strategyID int, revision int) (*models.StrategyRevision, error) {
+	query := `
+	SELECT id, strategy_id, revision, created_by, created_at, snapshot
+	FROM strategy_revisions
+	WHERE strategy_id = ? AND revision = ?`
+
+	var (
+		rev  models.StrategyRevision
+		data string
+	)
+
+	if err := q.QueryRowContext(ctx, query, strategyID, revision).Scan(&rev.ID, &rev.StrategyID, &rev.Revision, &rev.CreatedBy, &rev.CreatedAt, &data); err != nil {
+		return nil, fmt.Errorf("failed to retrieve revision: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(data), &rev.Snapshot); err != nil {
+		return nil, fmt.Errorf("decoding snapshot: %w", err)
+	}
+
+	return &rev, nil
+}
+
+func (s *libsqlDB) RetrieveStrategyRevision(ctx context.Context, strategyID int, revision int) (*models.StrategyRevision, error) {
+	return retrieveRevision(ctx, s.db, strategyID, revision)
+}
+
+// RestoreStrategyRevision replaces the strategy's content with the given
+// revision and records the result as a new revision. A zero version skips
+// the version check. It returns the new version of the strategy.
+func (s *libsqlDB) RestoreStrategyRevision(ctx context.Context, strategyID int, revision int, version int, createdBy string) (*models.StrategyRevision, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rev, err := retrieveRevision(ctx, tx, strategyID, revision)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// revisions recorded before leagues were selectable keep the current one
+	query := `
+	UPDATE strategies
+	SET name = ?, description = ?, atlas = ?, atlas_tree = ?, duration = ?, league = COALESCE(NULLIF(?, ''), league), version = version + 1, updated_at = unixepoch()
+	WHERE id = ? AND deleted_at IS NULL AND (? = 0 OR version = ?)
+	RETURNING version`
+
+	var updated int
+	err = tx.QueryRowContext(ctx, query, rev.Snapshot.Name, rev.Snapshot.Description, rev.Snapshot.Atlas, rev.Snapshot.AtlasTree, rev.Snapshot.Duration, rev.Snapshot.League, strategyID, version, version).Scan(&updated)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = versionConflict(ctx, tx, `SELECT 1 FROM strategies WHERE id = ? AND deleted_at IS NULL`, strategyID)
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("restoring strategy: %w", err)
+	}
+
+	if err := restoreSnapshotTables(ctx, tx, strategyID, rev.Snapshot.Tables); err != nil {
+		return nil, 0, err
+	}
+
+	restored, err := createRevision(ctx, tx, strategyID, createdBy)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return restored, updated, tx.Commit()
+}
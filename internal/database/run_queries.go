@@ -0,0 +1,232 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+const runColumns = `id, strategy_id, user_id, username, league, duration, notes, created_at`
+
+func scanRun(row scanner) (*models.StrategyRun, error) {
+	var r models.StrategyRun
+	if err := row.Scan(&r.ID, &r.StrategyID, &r.UserID, &r.Username, &r.League, &r.Duration, &r.Notes, &r.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	r.Items = make([]models.RunItem, 0)
+
+	return &r, nil
+}
+
+// StoreRun records a run and its item quantities in a single transaction.
+// Items that do not belong to the strategy are skipped.
+func (s *libsqlDB) StoreRun(ctx context.Context, run models.StrategyRun) (*models.StrategyRun, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+	INSERT INTO strategy_runs (strategy_id, user_id, username, league, duration, notes)
+	VALUES (?, ?, ?, ?, ?, ?)
+	RETURNING ` + runColumns
+
+	stored, err := scanRun(tx.QueryRowContext(ctx, query, run.StrategyID, run.UserID, run.Username, run.League, run.Duration, run.Notes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to store run: %w", err)
+	}
+
+	itemQuery := `
+	INSERT INTO strategy_run_items (run_id, strategy_item_id, quantity)
+	SELECT ?, id, ?
+	FROM strategy_items
+	WHERE id = ? AND strategy_id = ?`
+
+	for _, item := range run.Items {
+		res, err := tx.ExecContext(ctx, itemQuery, stored.ID, item.Quantity, item.StrategyItemID, run.StrategyID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to store run item: %w", err)
+		}
+
+		if n, err := res.RowsAffected(); err == nil && n > 0 {
+			stored.Items = append(stored.Items, item)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit run: %w", err)
+	}
+
+	return stored, nil
+}
+
+func (s *libsqlDB) RetrieveRun(ctx context.Context, strategyID int, runID int) (*models.StrategyRun, error) {
+	query := `
+	SELECT ` + runColumns + `
+	FROM strategy_runs
+	WHERE id = ? AND strategy_id = ?`
+
+	run, err := scanRun(s.db.QueryRowContext(ctx, query, runID, strategyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve run: %w", err)
+	}
+
+	if err := s.attachRunItems(ctx, []*models.StrategyRun{run}); err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// ListRuns paginates the recorded runs of a strategy, newest first.
+func (s *libsqlDB) ListRuns(ctx context.Context, strategyID int, limit int, offset int) ([]models.StrategyRun, int, error) {
+	var total int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM strategy_runs WHERE strategy_id = ?`, strategyID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("counting runs: %w", err)
+	}
+
+	query := `
+	SELECT ` + runColumns + `
+	FROM strategy_runs
+	WHERE strategy_id = ?
+	ORDER BY created_at DESC, id DESC
+	LIMIT ?
+	OFFSET ?`
+
+	rows, err := s.db.QueryContext(ctx, query, strategyID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("listing runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := make([]*models.StrategyRun, 0)
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scanning run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	if err := s.attachRunItems(ctx, runs); err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]models.StrategyRun, len(runs))
+	for i, run := range runs {
+		result[i] = *run
+	}
+
+	return result, total, nil
+}
+
+func (s *libsqlDB) attachRunItems(ctx context.Context, runs []*models.StrategyRun) error {
+	if len(runs) == 0 {
+		return nil
+	}
+
+	index := make(map[int]*models.StrategyRun, len(runs))
+	args := make([]any, len(runs))
+	for i, run := range runs {
+		index[run.ID] = run
+		args[i] = run.ID
+	}
+
+	query := fmt.Sprintf(`
+	SELECT run_id, strategy_item_id, quantity
+	FROM strategy_run_items
+	WHERE run_id IN (%s)
+	ORDER BY run_id, strategy_item_id`, strings.TrimSuffix(strings.Repeat("?, ", len(runs)), ", "))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("retrieving run items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			runID int
+			item  models.RunItem
+		)
+
+		if err := rows.Scan(&runID, &item.StrategyItemID, &item.Quantity); err != nil {
+			return fmt.Errorf("scanning run item: %w", err)
+		}
+
+		if run, ok := index[runID]; ok {
+			run.Items = append(run.Items, item)
+		}
+	}
+
+	return rows.Err()
+}
+
+func (s *libsqlDB) DeleteRun(ctx context.Context, strategyID int, runID int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM strategy_run_items WHERE run_id IN (SELECT id FROM strategy_runs WHERE id = ? AND strategy_id = ?)`, runID, strategyID); err != nil {
+		return fmt.Errorf("failed to delete run items: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM strategy_runs WHERE id = ? AND strategy_id = ?`, runID, strategyID)
+	if err != nil {
+		return fmt.Errorf("failed to delete run: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("failed to delete run: %w", sql.ErrNoRows)
+	}
+
+	return tx.Commit()
+}
+
+// RetrieveRunTotals sums the recorded runs of a strategy in a league.
+func (s *libsqlDB) RetrieveRunTotals(ctx context.Context, strategyID int, league string) (*models.RunTotals, error) {
+	totals := models.RunTotals{Quantities: make(map[int]int)}
+
+	query := `
+	SELECT COUNT(*), COALESCE(SUM(duration), 0)
+	FROM strategy_runs
+	WHERE strategy_id = ? AND league = ?`
+
+	if err := s.db.QueryRowContext(ctx, query, strategyID, league).Scan(&totals.Runs, &totals.Duration); err != nil {
+		return nil, fmt.Errorf("failed to retrieve run totals: %w", err)
+	}
+
+	itemsQuery := `
+	SELECT ri.strategy_item_id, SUM(ri.quantity)
+	FROM strategy_run_items ri
+	JOIN strategy_runs r ON r.id = ri.run_id
+	WHERE r.strategy_id = ? AND r.league = ?
+	GROUP BY ri.strategy_item_id`
+
+	rows, err := s.db.QueryContext(ctx, itemsQuery, strategyID, league)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving run item totals: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, quantity int
+		if err := rows.Scan(&id, &quantity); err != nil {
+			return nil, fmt.Errorf("scanning run item total: %w", err)
+		}
+		totals.Quantities[id] = quantity
+	}
+
+	return &totals, rows.Err()
+}
@@ -9,7 +9,24 @@ CREATE TABLE users (
   role TEXT DEFAULT 'user'
 );
 
-CREATE TABLE refresh_tokens (user_id TEXT, token_id TEXT, expires_at TEXT);
+CREATE TABLE refresh_tokens (
+  user_id TEXT,
+  token_id TEXT,
+  family_id TEXT,
+  expires_at TEXT,
+  rotated_at INTEGER,
+  created_at INTEGER,
+  last_used_at INTEGER,
+  user_agent TEXT,
+  ip TEXT
+);
+
+CREATE INDEX idx_refresh_tokens_token ON refresh_tokens (token_id);
+
+CREATE INDEX idx_refresh_tokens_family ON refresh_tokens (user_id, family_id);
+
+-- id is either the id of a single access token or a token family
+CREATE TABLE revoked_tokens (id TEXT PRIMARY KEY, expires_at INTEGER NOT NULL);
 
 CREATE TABLE stats (id TEXT PRIMARY KEY, text TEXT, type TEXT);
 
@@ -81,3 +98,184 @@ CREATE TABLE queries (
   run_once BOOLEAN,
   FOREIGN KEY (item_id) REFERENCES items (id) ON DELETE CASCADE
 );
+
+CREATE TABLE strategies (
+  id INTEGER PRIMARY KEY,
+  user_id TEXT NOT NULL,
+  created_by TEXT NOT NULL,
+  name TEXT NOT NULL,
+  description TEXT DEFAULT '',
+  atlas TEXT DEFAULT '',
+  atlas_tree TEXT,
+  duration INTEGER DEFAULT 0,
+  league TEXT CHECK (league IN ('csc', 'chc')) DEFAULT 'csc',
+  public BOOLEAN DEFAULT 0,
+  featured BOOLEAN DEFAULT 0,
+  featured_position INTEGER DEFAULT 0,
+  featured_until INTEGER,
+  forked_from INTEGER,
+  forked_from_by TEXT,
+  fork_count INTEGER DEFAULT 0,
+  like_count INTEGER DEFAULT 0,
+  view_count INTEGER DEFAULT 0,
+  popularity REAL DEFAULT 0,
+  version INTEGER DEFAULT 1,
+  created_at INTEGER DEFAULT (unixepoch ()),
+  updated_at INTEGER DEFAULT (unixepoch ()),
+  deleted_at INTEGER,
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_strategies_user ON strategies (user_id);
+
+CREATE INDEX idx_strategies_deleted ON strategies (deleted_at);
+
+CREATE INDEX idx_strategies_featured ON strategies (featured, featured_position);
+
+CREATE INDEX idx_strategies_popularity ON strategies (public, popularity);
+
+CREATE TABLE strategy_tables (
+  id INTEGER PRIMARY KEY,
+  strategy_id INTEGER NOT NULL,
+  type TEXT DEFAULT '',
+  title TEXT DEFAULT '',
+  scale REAL DEFAULT 1,
+  version INTEGER DEFAULT 1,
+  FOREIGN KEY (strategy_id) REFERENCES strategies (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_strategy_tables_strategy ON strategy_tables (strategy_id);
+
+CREATE TABLE strategy_items (
+  id INTEGER PRIMARY KEY,
+  strategy_id INTEGER NOT NULL,
+  table_id INTEGER NOT NULL,
+  item_id TEXT NOT NULL,
+  amount INTEGER DEFAULT 1,
+  role TEXT DEFAULT '',
+  drop_chance REAL DEFAULT 1,
+  pair INTEGER DEFAULT 0,
+  price_override REAL,
+  version INTEGER DEFAULT 1,
+  FOREIGN KEY (strategy_id) REFERENCES strategies (id) ON DELETE CASCADE,
+  FOREIGN KEY (table_id) REFERENCES strategy_tables (id) ON DELETE CASCADE,
+  FOREIGN KEY (item_id) REFERENCES items (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_strategy_items_table ON strategy_items (strategy_id, table_id);
+
+CREATE TABLE strategy_revisions (
+  id INTEGER PRIMARY KEY,
+  strategy_id INTEGER NOT NULL,
+  revision INTEGER NOT NULL,
+  created_by TEXT NOT NULL,
+  snapshot TEXT NOT NULL,
+  created_at INTEGER DEFAULT (unixepoch ()),
+  UNIQUE (strategy_id, revision),
+  FOREIGN KEY (strategy_id) REFERENCES strategies (id) ON DELETE CASCADE
+);
+
+CREATE TABLE strategy_likes (
+  strategy_id INTEGER NOT NULL,
+  user_id TEXT NOT NULL,
+  created_at INTEGER DEFAULT (unixepoch ()),
+  PRIMARY KEY (strategy_id, user_id),
+  FOREIGN KEY (strategy_id) REFERENCES strategies (id) ON DELETE CASCADE,
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE strategy_views (
+  strategy_id INTEGER NOT NULL,
+  viewer TEXT NOT NULL,
+  viewed_at INTEGER DEFAULT (unixepoch ()),
+  PRIMARY KEY (strategy_id, viewer),
+  FOREIGN KEY (strategy_id) REFERENCES strategies (id) ON DELETE CASCADE
+);
+
+CREATE TABLE strategy_comments (
+  id INTEGER PRIMARY KEY,
+  strategy_id INTEGER NOT NULL,
+  user_id TEXT NOT NULL,
+  author TEXT NOT NULL,
+  parent_id INTEGER,
+  root_id INTEGER,
+  body TEXT NOT NULL,
+  created_at INTEGER DEFAULT (unixepoch ()),
+  updated_at INTEGER DEFAULT (unixepoch ()),
+  deleted_at INTEGER,
+  deleted_by TEXT,
+  FOREIGN KEY (strategy_id) REFERENCES strategies (id) ON DELETE CASCADE,
+  FOREIGN KEY (parent_id) REFERENCES strategy_comments (id)
+);
+
+CREATE INDEX idx_strategy_comments_thread ON strategy_comments (strategy_id, root_id, created_at);
+
+CREATE TABLE strategy_share_links (
+  id INTEGER PRIMARY KEY,
+  strategy_id INTEGER NOT NULL,
+  token TEXT NOT NULL UNIQUE,
+  created_at INTEGER DEFAULT (unixepoch ()),
+  expires_at INTEGER,
+  revoked_at INTEGER,
+  FOREIGN KEY (strategy_id) REFERENCES strategies (id) ON DELETE CASCADE
+);
+
+CREATE TABLE strategy_collaborators (
+  strategy_id INTEGER NOT NULL,
+  user_id TEXT NOT NULL,
+  username TEXT NOT NULL,
+  role TEXT CHECK (role IN ('viewer', 'editor')) DEFAULT 'viewer',
+  status TEXT CHECK (status IN ('pending', 'accepted')) DEFAULT 'pending',
+  invited_by TEXT NOT NULL,
+  created_at INTEGER DEFAULT (unixepoch ()),
+  PRIMARY KEY (strategy_id, user_id),
+  FOREIGN KEY (strategy_id) REFERENCES strategies (id) ON DELETE CASCADE,
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_strategy_collaborators_user ON strategy_collaborators (user_id, status);
+
+CREATE TABLE strategy_runs (
+  id INTEGER PRIMARY KEY,
+  strategy_id INTEGER NOT NULL,
+  user_id TEXT NOT NULL,
+  username TEXT NOT NULL,
+  league TEXT CHECK (league IN ('csc', 'chc')) DEFAULT 'csc',
+  duration INTEGER NOT NULL,
+  notes TEXT DEFAULT '',
+  created_at INTEGER DEFAULT (unixepoch ()),
+  FOREIGN KEY (strategy_id) REFERENCES strategies (id) ON DELETE CASCADE,
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_strategy_runs_strategy ON strategy_runs (strategy_id, league, created_at);
+
+CREATE TABLE strategy_run_items (
+  run_id INTEGER NOT NULL,
+  strategy_item_id INTEGER NOT NULL,
+  quantity INTEGER NOT NULL,
+  PRIMARY KEY (run_id, strategy_item_id),
+  FOREIGN KEY (run_id) REFERENCES strategy_runs (id) ON DELETE CASCADE
+);
+
+CREATE TABLE strategy_tags (
+  strategy_id INTEGER NOT NULL,
+  tag TEXT NOT NULL,
+  category TEXT CHECK (category IN ('mechanic', 'map', 'league')) NOT NULL,
+  PRIMARY KEY (strategy_id, tag),
+  FOREIGN KEY (strategy_id) REFERENCES strategies (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_strategy_tags_tag ON strategy_tags (tag, category);
+
+CREATE TABLE strategy_templates (
+  id INTEGER PRIMARY KEY,
+  name TEXT NOT NULL,
+  description TEXT DEFAULT '',
+  atlas TEXT DEFAULT '',
+  duration INTEGER DEFAULT 0,
+  tables TEXT NOT NULL DEFAULT '[]',
+  created_by TEXT NOT NULL,
+  created_at INTEGER DEFAULT (unixepoch ()),
+  updated_at INTEGER DEFAULT (unixepoch ())
+);
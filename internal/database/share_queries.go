@@ -0,0 +1,102 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+const shareLinkColumns = `id, strategy_id, token, created_at, expires_at, revoked_at`
+
+func scanShareLink(row scanner) (*models.ShareLink, error) {
+	var l models.ShareLink
+	if err := row.Scan(&l.ID, &l.StrategyID, &l.Token, &l.CreatedAt, &l.ExpiresAt, &l.RevokedAt); err != nil {
+		return nil, err
+	}
+
+	return &l, nil
+}
+
+func (s *libsqlDB) StoreShareLink(ctx context.Context, strategyID int, token string, expiresAt *int64) (*models.ShareLink, error) {
+	query := `
+	INSERT INTO strategy_share_links (strategy_id, token, expires_at)
+	VALUES (?, ?, ?)
+	RETURNING ` + shareLinkColumns
+
+	link, err := scanShareLink(s.db.QueryRowContext(ctx, query, strategyID, token, expiresAt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to store share link: %w", err)
+	}
+
+	return link, nil
+}
+
+func (s *libsqlDB) ListShareLinks(ctx context.Context, strategyID int) ([]models.ShareLink, error) {
+	query := `
+	SELECT ` + shareLinkColumns + `
+	FROM strategy_share_links
+	WHERE strategy_id = ?
+	ORDER BY created_at DESC, id DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, strategyID)
+	if err != nil {
+		return nil, fmt.Errorf("listing share links: %w", err)
+	}
+	defer rows.Close()
+
+	links := make([]models.ShareLink, 0)
+	for rows.Next() {
+		l, err := scanShareLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning share link: %w", err)
+		}
+		links = append(links, *l)
+	}
+
+	return links, rows.Err()
+}
+
+func (s *libsqlDB) RevokeShareLink(ctx context.Context, strategyID int, linkID int) error {
+	query := `
+	UPDATE strategy_share_links
+	SET revoked_at = unixepoch()
+	WHERE id = ? AND strategy_id = ? AND revoked_at IS NULL`
+
+	res, err := s.db.ExecContext(ctx, query, linkID, strategyID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke share link: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("failed to revoke share link: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// IsShareTokenValid reports whether the token grants read access to the
+// strategy, i.e. it exists, is not revoked and has not expired.
+func (s *libsqlDB) IsShareTokenValid(ctx context.Context, strategyID int, token string) (bool, error) {
+	query := `
+	SELECT 1
+	FROM strategy_share_links
+	WHERE
+		strategy_id = ?
+		AND token = ?
+		AND revoked_at IS NULL
+		AND (expires_at IS NULL OR expires_at > unixepoch())`
+
+	var valid int
+	err := s.db.QueryRowContext(ctx, query, strategyID, token).Scan(&valid)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to validate share token: %w", err)
+	}
+
+	return true, nil
+}
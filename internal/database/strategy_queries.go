@@ -1,78 +1,602 @@
 package database
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/Vyary/api/internal/models"
 )
 
-func (s *libsqlDB) StoreStrategy(user models.UserProfile, strategy models.Strategy) (*models.StrategyDTO, error) {
+// ErrVersionConflict is returned when a conditional write targets a row
+// whose version has changed since it was read.
+var ErrVersionConflict = errors.New("version conflict")
+
+// likeEscaper escapes the wildcards of user input placed in a LIKE pattern
+// with ESCAPE '\'.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx so helpers can run
+// inside or outside of a transaction.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+const strategyColumns = `id, user_id, created_by, name, description, atlas, atlas_tree, duration, league, public, featured, forked_from, COALESCE(forked_from_by, ''), fork_count, like_count, view_count, version, created_at, updated_at, deleted_at`
+
+// strategyFields returns the scan destinations matching strategyColumns.
+func strategyFields(st *models.Strategy) []any {
+	return []any{&st.ID, &st.UserID, &st.CreatedBy, &st.Name, &st.Description, &st.Atlas, &st.AtlasTree, &st.Duration, &st.League, &st.Public, &st.Featured, &st.ForkedFrom, &st.ForkedBy, &st.ForkCount, &st.LikeCount, &st.ViewCount, &st.Version, &st.CreatedAt, &st.UpdatedAt, &st.DeletedAt}
+}
+
+// versionConflict explains why a conditional write matched no row: it
+// returns ErrVersionConflict when the row selected by query still exists and
+// sql.ErrNoRows otherwise.
+func versionConflict(ctx context.Context, q querier, query string, args ...any) error {
+	var exists int
+	err := q.QueryRowContext(ctx, query, args...).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	return ErrVersionConflict
+}
+
+// touchStrategy bumps the version of a strategy whose tables, items or tags
+// changed, so its ETag changes with its content. A zero version skips the
+// version check. It returns the new version.
+func touchStrategy(ctx context.Context, q querier, strategyID int, version int) (int, error) {
+	query := `
+	UPDATE strategies
+	SET version = version + 1, updated_at = unixepoch()
+	WHERE id = ? AND deleted_at IS NULL AND (? = 0 OR version = ?)
+	RETURNING version`
+
+	var updated int
+	err := q.QueryRowContext(ctx, query, strategyID, version, version).Scan(&updated)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = versionConflict(ctx, q, `SELECT 1 FROM strategies WHERE id = ? AND deleted_at IS NULL`, strategyID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("updating strategy version: %w", err)
+	}
+
+	return updated, nil
+}
+
+func scanStrategy(row scanner) (*models.Strategy, error) {
+	var st models.Strategy
+	if err := row.Scan(strategyFields(&st)...); err != nil {
+		return nil, err
+	}
+
+	return &st, nil
+}
+
+// StoreStrategy creates a strategy together with its first revision.
+func (s *libsqlDB) StoreStrategy(ctx context.Context, user models.UserProfile, strategy models.Strategy) (*models.StrategyDTO, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
-	INSERT INTO strategies (user_id, created_by, name, description, atlas, public) 
-	VALUES (?, ?, ?, ?, ?, ?) 
-	RETURNING id, created_by, name, description, atlas, public, created_at, updated_at`
+	INSERT INTO strategies (user_id, created_by, name, description, atlas, atlas_tree, duration, league, public)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	RETURNING ` + strategyColumns
 
-	var strategyDTO models.StrategyDTO
-	if err := s.db.QueryRow(query, user.ID, user.Name, strategy.Name, strategy.Description, strategy.Atlas, strategy.Public).Scan(&strategyDTO.ID, &strategyDTO.CreatedBy, &strategyDTO.Name, &strategyDTO.Description, &strategyDTO.Atlas, &strategyDTO.Public, &strategyDTO.CreatedAt, &strategyDTO.UpdatedAt); err != nil {
+	stored, err := scanStrategy(tx.QueryRowContext(ctx, query, user.ID, user.Name, strategy.Name, strategy.Description, strategy.Atlas, strategy.AtlasTree, strategy.Duration, strategy.League, strategy.Public))
+	if err != nil {
 		return nil, fmt.Errorf("failed to create strategy: %w", err)
 	}
 
+	if _, err := createRevision(ctx, tx, stored.ID, user.Name); err != nil {
+		return nil, fmt.Errorf("failed to record revision: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	strategyDTO := stored.DTO()
+
 	return &strategyDTO, nil
 }
 
-func (s *libsqlDB) StoreStrategyTable(strategyID string, table models.StrategyTable) (*models.StrategyTable, error) {
+func (s *libsqlDB) RetrieveStrategy(ctx context.Context, id int) (*models.Strategy, error) {
 	query := `
-	INSERT INTO strategy_tables (strategy_id, type, title)
-	VALUES (?, ?, ?)
-	RETURNING id, strategy_id, type, title`
+	SELECT ` + strategyColumns + `
+	FROM strategies
+	WHERE id = ? AND deleted_at IS NULL`
+
+	strategy, err := scanStrategy(s.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve strategy: %w", err)
+	}
+
+	return strategy, nil
+}
+
+// UpdateStrategy replaces the editable fields of a strategy. Making it
+// private drops its featured slot.
+func (s *libsqlDB) UpdateStrategy(ctx context.Context, strategy models.Strategy, createdBy string) (*models.Strategy, error) {
+	query := `
+	UPDATE strategies
+	SET
+		name = ?,
+		description = ?,
+		atlas = ?,
+		atlas_tree = ?,
+		duration = ?,
+		league = ?,
+		public = ?,
+		featured = CASE WHEN ? THEN featured ELSE 0 END,
+		featured_position = CASE WHEN ? THEN featured_position ELSE 0 END,
+		featured_until = CASE WHEN ? THEN featured_until ELSE NULL END,
+		version = version + 1,
+		updated_at = unixepoch()
+	WHERE id = ? AND deleted_at IS NULL AND (? = 0 OR version = ?)
+	RETURNING ` + strategyColumns
+
+	var updated *models.Strategy
+	err := s.revise(ctx, strategy.ID, createdBy, func(q querier) error {
+		var err error
+		updated, err = scanStrategy(q.QueryRowContext(ctx, query, strategy.Name, strategy.Description, strategy.Atlas, strategy.AtlasTree, strategy.Duration, strategy.League, strategy.Public, strategy.Public, strategy.Public, strategy.Public, strategy.ID, strategy.Version, strategy.Version))
+		if errors.Is(err, sql.ErrNoRows) {
+			err = versionConflict(ctx, q, `SELECT 1 FROM strategies WHERE id = ? AND deleted_at IS NULL`, strategy.ID)
+		}
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update strategy: %w", err)
+	}
 
+	return updated, nil
+}
+
+// DeleteStrategy moves a strategy to its owner's trash. It stays hidden
+// from every query until it is restored or purged and loses its featured
+// slot. A zero version skips the version check.
+func (s *libsqlDB) DeleteStrategy(ctx context.Context, id int, version int) error {
+	query := `
+	UPDATE strategies
+	SET
+		deleted_at = unixepoch(),
+		featured = 0,
+		featured_position = 0,
+		featured_until = NULL,
+		version = version + 1
+	WHERE id = ? AND deleted_at IS NULL AND (? = 0 OR version = ?)`
+
+	res, err := s.db.ExecContext(ctx, query, id, version, version)
+	if err != nil {
+		return fmt.Errorf("failed to delete strategy: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		err := versionConflict(ctx, s.db, `SELECT 1 FROM strategies WHERE id = ? AND deleted_at IS NULL`, id)
+		return fmt.Errorf("failed to delete strategy: %w", err)
+	}
+
+	return nil
+}
+
+// purgeStrategy permanently removes a strategy together with its tables,
+// items and every other child row. Children are deleted explicitly since
+// foreign key enforcement is not guaranteed to be enabled on the replica
+// connection.
+func purgeStrategy(ctx context.Context, q querier, id int) error {
+	if _, err := q.ExecContext(ctx, `DELETE FROM strategy_tags WHERE strategy_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete strategy tags: %w", err)
+	}
+
+	if _, err := q.ExecContext(ctx, `DELETE FROM strategy_run_items WHERE run_id IN (SELECT id FROM strategy_runs WHERE strategy_id = ?)`, id); err != nil {
+		return fmt.Errorf("failed to delete strategy run items: %w", err)
+	}
+
+	if _, err := q.ExecContext(ctx, `DELETE FROM strategy_runs WHERE strategy_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete strategy runs: %w", err)
+	}
+
+	if _, err := q.ExecContext(ctx, `DELETE FROM strategy_items WHERE strategy_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete strategy items: %w", err)
+	}
+
+	if _, err := q.ExecContext(ctx, `DELETE FROM strategy_revisions WHERE strategy_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete strategy revisions: %w", err)
+	}
+
+	if _, err := q.ExecContext(ctx, `DELETE FROM strategy_likes WHERE strategy_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete strategy likes: %w", err)
+	}
+
+	if _, err := q.ExecContext(ctx, `DELETE FROM strategy_views WHERE strategy_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete strategy views: %w", err)
+	}
+
+	if _, err := q.ExecContext(ctx, `DELETE FROM strategy_comments WHERE strategy_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete strategy comments: %w", err)
+	}
+
+	if _, err := q.ExecContext(ctx, `DELETE FROM strategy_share_links WHERE strategy_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete strategy share links: %w", err)
+	}
+
+	if _, err := q.ExecContext(ctx, `DELETE FROM strategy_collaborators WHERE strategy_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete strategy collaborators: %w", err)
+	}
+
+	if _, err := q.ExecContext(ctx, `DELETE FROM strategy_tables WHERE strategy_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete strategy tables: %w", err)
+	}
+
+	if _, err := q.ExecContext(ctx, `DELETE FROM strategies WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete strategy: %w", err)
+	}
+
+	return nil
+}
+
+const strategyTableColumns = `id, strategy_id, type, title, scale, version`
+
+func scanStrategyTable(row scanner) (*models.StrategyTable, error) {
 	var st models.StrategyTable
-	if err := s.db.QueryRow(query, strategyID, table.Type, table.Title).Scan(&st.ID, &st.StrategyID, &st.Type, &st.Title); err != nil {
-		return nil, fmt.Errorf("failed to create table: %w", err)
+	if err := row.Scan(&st.ID, &st.StrategyID, &st.Type, &st.Title, &st.Scale, &st.Version); err != nil {
+		return nil, err
 	}
 
 	return &st, nil
 }
 
-func (s *libsqlDB) RetrieveStrategy(id string) (*models.Strategy, error) {
-	strategyQuery := `
-	SELECT *
-	FROM strategies 
-	WHERE id = ?`
+func (s *libsqlDB) StoreStrategyTable(ctx context.Context, strategyID int, table models.StrategyTable, createdBy string) (*models.StrategyTable, error) {
+	query := `
+	INSERT INTO strategy_tables (strategy_id, type, title, scale)
+	VALUES (?, ?, ?, ?)
+	RETURNING ` + strategyTableColumns
 
-	// TODO: create DTO for strategy
-	var strategy models.Strategy
+	var st *models.StrategyTable
+	err := s.revise(ctx, strategyID, createdBy, func(q querier) error {
+		var err error
+		st, err = scanStrategyTable(q.QueryRowContext(ctx, query, strategyID, table.Type, table.Title, table.Scale))
+		if err != nil {
+			return err
+		}
 
-	err := s.db.QueryRow(strategyQuery, id).Scan(&strategy.UserID, &strategy.CreatedBy, &strategy.Name, &strategy.Description, &strategy.Atlas, &strategy.Public, &strategy.CreatedAt, &strategy.UpdatedAt)
+		_, err = touchStrategy(ctx, q, strategyID, 0)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve strategy: %w", err)
+		return nil, fmt.Errorf("failed to create table: %w", err)
 	}
 
-	itemsQuery := `
-	SELECT si.*, i.*
-	FROM strategy_items si
-	JOIN items i ON si.item_id = i.id
+	return st, nil
+}
+
+func (s *libsqlDB) RetrieveStrategyTables(ctx context.Context, strategyID int) ([]models.StrategyTable, error) {
+	query := `
+	SELECT ` + strategyTableColumns + `
+	FROM strategy_tables
 	WHERE strategy_id = ?
-	`
+	ORDER BY id`
 
-	rows, err := s.db.Query(itemsQuery, id)
+	rows, err := s.db.QueryContext(ctx, query, strategyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve strategy tables: %w", err)
+	}
+	defer rows.Close()
+
+	tables := make([]models.StrategyTable, 0)
+
+	for rows.Next() {
+		st, err := scanStrategyTable(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning strategy table: %w", err)
+		}
+		tables = append(tables, *st)
+	}
+
+	return tables, rows.Err()
+}
+
+func (s *libsqlDB) UpdateStrategyTable(ctx context.Context, table models.StrategyTable, createdBy string) (*models.StrategyTable, error) {
+	query := `
+	UPDATE strategy_tables
+	SET type = ?, title = ?, scale = ?, version = version + 1
+	WHERE id = ? AND strategy_id = ? AND (? = 0 OR version = ?)
+	RETURNING ` + strategyTableColumns
+
+	var st *models.StrategyTable
+	err := s.revise(ctx, table.StrategyID, createdBy, func(q querier) error {
+		var err error
+		st, err = scanStrategyTable(q.QueryRowContext(ctx, query, table.Type, table.Title, table.Scale, table.ID, table.StrategyID, table.Version, table.Version))
+		if errors.Is(err, sql.ErrNoRows) {
+			err = versionConflict(ctx, q, `SELECT 1 FROM strategy_tables WHERE id = ? AND strategy_id = ?`, table.ID, table.StrategyID)
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = touchStrategy(ctx, q, table.StrategyID, 0)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update table: %w", err)
+	}
+
+	return st, nil
+}
+
+func (s *libsqlDB) DeleteStrategyTable(ctx context.Context, strategyID int, tableID int, version int, createdBy string) error {
+	return s.revise(ctx, strategyID, createdBy, func(q querier) error {
+		res, err := q.ExecContext(ctx, `DELETE FROM strategy_tables WHERE id = ? AND strategy_id = ? AND (? = 0 OR version = ?)`, tableID, strategyID, version, version)
+		if err != nil {
+			return fmt.Errorf("failed to delete table: %w", err)
+		}
+
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			err := versionConflict(ctx, q, `SELECT 1 FROM strategy_tables WHERE id = ? AND strategy_id = ?`, tableID, strategyID)
+			return fmt.Errorf("failed to delete table: %w", err)
+		}
+
+		if _, err := q.ExecContext(ctx, `DELETE FROM strategy_items WHERE table_id = ? AND strategy_id = ?`, tableID, strategyID); err != nil {
+			return fmt.Errorf("failed to delete table items: %w", err)
+		}
+
+		_, err = touchStrategy(ctx, q, strategyID, 0)
+		return err
+	})
+}
+
+const strategyItemColumns = `id, strategy_id, table_id, item_id, amount, role, drop_chance, pair, price_override, version`
+
+func scanStrategyItem(row scanner) (*models.StrategyItem, error) {
+	var si models.StrategyItem
+	if err := row.Scan(&si.SID, &si.StrategyID, &si.TableID, &si.ItemID, &si.Amount, &si.Role, &si.DropChance, &si.Pair, &si.PriceOverride, &si.Version); err != nil {
+		return nil, err
+	}
+
+	return &si, nil
+}
+
+// StoreStrategyItem adds an item to one of the strategy's tables. It returns
+// sql.ErrNoRows when the table does not belong to the strategy.
+func (s *libsqlDB) StoreStrategyItem(ctx context.Context, strategyID int, item models.StrategyItem, createdBy string) (*models.StrategyItem, error) {
+	query := `
+	INSERT INTO strategy_items (strategy_id, table_id, item_id, amount, role, drop_chance, pair, price_override)
+	SELECT ?, ?, ?, ?, ?, ?, ?, ?
+	WHERE EXISTS (SELECT 1 FROM strategy_tables WHERE id = ? AND strategy_id = ?)
+	RETURNING ` + strategyItemColumns
+
+	var stored *models.StrategyItem
+	err := s.revise(ctx, strategyID, createdBy, func(q querier) error {
+		var err error
+		stored, err = scanStrategyItem(q.QueryRowContext(ctx, query, strategyID, item.TableID, item.ItemID, item.Amount, item.Role, item.DropChance, item.Pair, item.PriceOverride, item.TableID, strategyID))
+		if err != nil {
+			return err
+		}
+
+		_, err = touchStrategy(ctx, q, strategyID, 0)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create strategy item: %w", err)
+	}
+
+	return stored, nil
+}
+
+// ExistingItemIDs reports which of the given ids exist in items.
+func (s *libsqlDB) ExistingItemIDs(ctx context.Context, ids []string) (map[string]bool, error) {
+	existing := make(map[string]bool, len(ids))
+	if len(ids) == 0 {
+		return existing, nil
+	}
+
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	query := fmt.Sprintf(`
+	SELECT id
+	FROM items
+	WHERE id IN (%s)`, strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", "))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("checking item ids: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scanning item id: %w", err)
+		}
+		existing[id] = true
+	}
+
+	return existing, rows.Err()
+}
+
+func (s *libsqlDB) RetrieveStrategyItems(ctx context.Context, strategyID int, tableID int) ([]models.StrategyItem, error) {
+	query := `
+	SELECT ` + strategyItemColumns + `
+	FROM strategy_items
+	WHERE strategy_id = ? AND table_id = ?
+	ORDER BY id`
+
+	rows, err := s.db.QueryContext(ctx, query, strategyID, tableID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve strategy items: %w", err)
 	}
+	defer rows.Close()
+
+	items := make([]models.StrategyItem, 0)
 
 	for rows.Next() {
-		// var item models.SItem
-		//
-		// if err := rows.Scan(&item.ID, &item.StrategyID, &item.Icon, &item.TableID, &item.Amount, &item.Role, &item.Pair, &item.DropChance, &item.Icon, &item.Name, &item.Base, &item.Category, &item.Value, &item.Currency, &item.Listed, &item.UserID, &item.CreatedAt, &item.UpdatedAt); err != nil {
-		// 	return nil, err
-		// }
-		//
+		si, err := scanStrategyItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning strategy item: %w", err)
+		}
+		items = append(items, *si)
+	}
+
+	return items, rows.Err()
+}
 
+func (s *libsqlDB) UpdateStrategyItem(ctx context.Context, item models.StrategyItem, createdBy string) (*models.StrategyItem, error) {
+	query := `
+	UPDATE strategy_items
+	SET item_id = ?, amount = ?, role = ?, drop_chance = ?, pair = ?, price_override = ?, version = version + 1
+	WHERE id = ? AND strategy_id = ? AND table_id = ? AND (? = 0 OR version = ?)
+	RETURNING ` + strategyItemColumns
+
+	var updated *models.StrategyItem
+	err := s.revise(ctx, item.StrategyID, createdBy, func(q querier) error {
+		var err error
+		updated, err = scanStrategyItem(q.QueryRowContext(ctx, query, item.ItemID, item.Amount, item.Role, item.DropChance, item.Pair, item.PriceOverride, item.SID, item.StrategyID, item.TableID, item.Version, item.Version))
+		if errors.Is(err, sql.ErrNoRows) {
+			err = versionConflict(ctx, q, `SELECT 1 FROM strategy_items WHERE id = ? AND strategy_id = ? AND table_id = ?`, item.SID, item.StrategyID, item.TableID)
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = touchStrategy(ctx, q, item.StrategyID, 0)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update strategy item: %w", err)
 	}
 
-	return &strategy, nil
+	return updated, nil
 }
 
-func (s *libsqlDB) StoreStrategyItem(strategyID string, item models.StrategyItem) error {
-	return nil
+// DeleteStrategyItem removes an item from one of the strategy's tables. A
+// zero version skips the version check.
+func (s *libsqlDB) DeleteStrategyItem(ctx context.Context, strategyID int, tableID int, itemID int, version int, createdBy string) error {
+	query := `
+	DELETE FROM strategy_items
+	WHERE id = ? AND strategy_id = ? AND table_id = ? AND (? = 0 OR version = ?)`
+
+	return s.revise(ctx, strategyID, createdBy, func(q querier) error {
+		res, err := q.ExecContext(ctx, query, itemID, strategyID, tableID, version, version)
+		if err != nil {
+			return fmt.Errorf("failed to delete strategy item: %w", err)
+		}
+
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			err := versionConflict(ctx, q, `SELECT 1 FROM strategy_items WHERE id = ? AND strategy_id = ? AND table_id = ?`, itemID, strategyID, tableID)
+			return fmt.Errorf("failed to delete strategy item: %w", err)
+		}
+
+		_, err = touchStrategy(ctx, q, strategyID, 0)
+		return err
+	})
+}
+
+func strategyOrderBy(sort string) string {
+	switch sort {
+	case models.StrategySortUpdated:
+		return "updated_at DESC, id DESC"
+	case models.StrategySortPopular:
+		return "popularity DESC, id DESC"
+	default:
+		return "created_at DESC, id DESC"
+	}
+}
+
+func (s *libsqlDB) ListStrategies(ctx context.Context, q models.StrategyQuery) ([]models.StrategyDTO, int, error) {
+	var (
+		where strings.Builder
+		args  []any
+	)
+
+	where.WriteString("deleted_at IS NULL")
+
+	if q.UserID != "" {
+		where.WriteString(" AND user_id = ?")
+		args = append(args, q.UserID)
+	} else {
+		where.WriteString(" AND public = 1")
+	}
+
+	if q.Search != "" {
+		pattern := "%" + likeEscaper.Replace(q.Search) + "%"
+		where.WriteString(` AND (name LIKE ? ESCAPE '\' OR description LIKE ? ESCAPE '\')`)
+		args = append(args, pattern, pattern)
+	}
+
+	if q.Atlas != "" {
+		where.WriteString(" AND atlas = ?")
+		args = append(args, q.Atlas)
+	}
+
+	if q.CreatedBy != "" {
+		where.WriteString(" AND created_by = ?")
+		args = append(args, q.CreatedBy)
+	}
+
+	if len(q.Tags) > 0 {
+		fmt.Fprintf(&where, " AND id IN (SELECT strategy_id FROM strategy_tags WHERE tag IN (%s) GROUP BY strategy_id HAVING COUNT(*) = ?)", strings.TrimSuffix(strings.Repeat("?, ", len(q.Tags)), ", "))
+		for _, tag := range q.Tags {
+			args = append(args, tag)
+		}
+		args = append(args, len(q.Tags))
+	}
+
+	countQuery := `
+	SELECT COUNT(*)
+	FROM strategies
+	WHERE ` + where.String()
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("counting strategies: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+	SELECT %s
+	FROM strategies
+	WHERE %s
+	ORDER BY %s
+	LIMIT ?
+	OFFSET ?`, strategyColumns, where.String(), strategyOrderBy(q.Sort))
+
+	rows, err := s.db.QueryContext(ctx, query, append(args, q.Limit, q.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("listing strategies: %w", err)
+	}
+	defer rows.Close()
+
+	strategies := make([]models.StrategyDTO, 0)
+
+	for rows.Next() {
+		st, err := scanStrategy(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scanning strategy: %w", err)
+		}
+		strategies = append(strategies, st.DTO())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	if err := s.attachTags(ctx, strategies); err != nil {
+		return nil, 0, err
+	}
+
+	return strategies, total, nil
 }
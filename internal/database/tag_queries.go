@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+func (s *libsqlDB) RetrieveStrategyTags(ctx context.Context, strategyID int) ([]models.Tag, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT tag, category FROM strategy_tags WHERE strategy_id = ? ORDER BY category, tag`, strategyID)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving strategy tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make([]models.Tag, 0)
+	for rows.Next() {
+		var t models.Tag
+		if err := rows.Scan(&t.Name, &t.Category); err != nil {
+			return nil, fmt.Errorf("scanning strategy tag: %w", err)
+		}
+		tags = append(tags, t)
+	}
+
+	return tags, rows.Err()
+}
+
+// SetStrategyTags replaces every tag of a strategy and returns its new
+// version. A zero version skips the version check.
+func (s *libsqlDB) SetStrategyTags(ctx context.Context, strategyID int, tags []models.Tag, version int) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	updated, err := touchStrategy(ctx, tx, strategyID, version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to set strategy tags: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM strategy_tags WHERE strategy_id = ?`, strategyID); err != nil {
+		return 0, fmt.Errorf("clearing strategy tags: %w", err)
+	}
+
+	if err := insertStrategyTags(ctx, tx, strategyID, tags); err != nil {
+		return 0, err
+	}
+
+	return updated, tx.Commit()
+}
+
+func insertStrategyTags(ctx context.Context, q querier, strategyID int, tags []models.Tag) error {
+	for _, t := range tags {
+		if _, err := q.ExecContext(ctx, `INSERT INTO strategy_tags (strategy_id, tag, category) VALUES (?, ?, ?)`, strategyID, t.Name, t.Category); err != nil {
+			return fmt.Errorf("storing strategy tag: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ListTagSuggestions autocompletes tags used by public strategies, most
+// used first. An empty category matches every category.
+func (s *libsqlDB) ListTagSuggestions(ctx context.Context, prefix string, category string, limit int) ([]models.TagSuggestion, error) {
+	query := `
+	SELECT t.tag, t.category, COUNT(*) AS uses
+	FROM strategy_tags t
+	JOIN strategies s ON s.id = t.strategy_id
+	WHERE s.public = 1 AND s.deleted_at IS NULL
+	AND t.tag LIKE ? ESCAPE '\'
+	AND (? = '' OR t.category = ?)
+	GROUP BY t.tag, t.category
+	ORDER BY uses DESC, t.tag
+	LIMIT ?`
+
+	pattern := likeEscaper.Replace(prefix) + "%"
+
+	rows, err := s.db.QueryContext(ctx, query, pattern, category, category, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing tag suggestions: %w", err)
+	}
+	defer rows.Close()
+
+	suggestions := make([]models.TagSuggestion, 0)
+	for rows.Next() {
+		var t models.TagSuggestion
+		if err := rows.Scan(&t.Name, &t.Category, &t.Count); err != nil {
+			return nil, fmt.Errorf("scanning tag suggestion: %w", err)
+		}
+		suggestions = append(suggestions, t)
+	}
+
+	return suggestions, rows.Err()
+}
+
+// attachTags loads the tags of a page of strategies in a single query.
+func (s *libsqlDB) attachTags(ctx context.Context, strategies []models.StrategyDTO) error {
+	if len(strategies) == 0 {
+		return nil
+	}
+
+	index := make(map[int]int, len(strategies))
+	args := make([]any, len(strategies))
+	for i, st := range strategies {
+		index[st.ID] = i
+		args[i] = st.ID
+		strategies[i].Tags = make([]models.Tag, 0)
+	}
+
+	query := fmt.Sprintf(`
+	SELECT strategy_id, tag, category
+	FROM strategy_tags
+	WHERE strategy_id IN (%s)
+	ORDER BY category, tag`, strings.TrimSuffix(strings.Repeat("?, ", len(strategies)), ", "))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("retrieving strategy tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			strategyID int
+			t          models.Tag
+		)
+
+		if err := rows.Scan(&strategyID, &t.Name, &t.Category); err != nil {
+			return fmt.Errorf("scanning strategy tag: %w", err)
+		}
+
+		if i, ok := index[strategyID]; ok {
+			strategies[i].Tags = append(strategies[i].Tags, t)
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+const templateColumns = `id, name, description, atlas, duration, tables, created_by, created_at, updated_at`
+
+func scanTemplate(row scanner) (*models.StrategyTemplate, error) {
+	var (
+		t      models.StrategyTemplate
+		tables string
+	)
+
+	if err := row.Scan(&t.ID, &t.Name, &t.Description, &t.Atlas, &t.Duration, &tables, &t.CreatedBy, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(tables), &t.Tables); err != nil {
+		return nil, fmt.Errorf("decoding template tables: %w", err)
+	}
+
+	return &t, nil
+}
+
+func (s *libsqlDB) StoreTemplate(ctx context.Context, template models.StrategyTemplate) (*models.StrategyTemplate, error) {
+	tables, err := json.Marshal(template.Tables)
+	if err != nil {
+		return nil, fmt.Errorf("encoding template tables: %w", err)
+	}
+
+	query := `
+	INSERT INTO strategy_templates (name, description, atlas, duration, tables, created_by)
+	VALUES (?, ?, ?, ?, ?, ?)
+	RETURNING ` + templateColumns
+
+	stored, err := scanTemplate(s.db.QueryRowContext(ctx, query, template.Name, template.Description, template.Atlas, template.Duration, string(tables), template.CreatedBy))
+	if err != nil {
+		return nil, fmt.Errorf("failed to store template: %w", err)
+	}
+
+	return stored, nil
+}
+
+func (s *libsqlDB) RetrieveTemplate(ctx context.Context, id int) (*models.StrategyTemplate, error) {
+	template, err := scanTemplate(s.db.QueryRowContext(ctx, `SELECT `+templateColumns+` FROM strategy_templates WHERE id = ?`, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve template: %w", err)
+	}
+
+	return template, nil
+}
+
+func (s *libsqlDB) ListTemplates(ctx context.Context) ([]models.StrategyTemplate, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+templateColumns+` FROM strategy_templates ORDER BY name, id`)
+	if err != nil {
+		return nil, fmt.Errorf("listing templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := make([]models.StrategyTemplate, 0)
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning template: %w", err)
+		}
+		templates = append(templates, *t)
+	}
+
+	return templates, rows.Err()
+}
+
+func (s *libsqlDB) UpdateTemplate(ctx context.Context, template models.StrategyTemplate) (*models.StrategyTemplate, error) {
+	tables, err := json.Marshal(template.Tables)
+	if err != nil {
+		return nil, fmt.Errorf("encoding template tables: %w", err)
+	}
+
+	query := `
+	UPDATE strategy_templates
+	SET
+		name = ?,
+		description = ?,
+		atlas = ?,
+		duration = ?,
+		tables = ?,
+		updated_at = unixepoch()
+	WHERE id = ?
+	RETURNING ` + templateColumns
+
+	updated, err := scanTemplate(s.db.QueryRowContext(ctx, query, template.Name, template.Description, template.Atlas, template.Duration, string(tables), template.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update template: %w", err)
+	}
+
+	return updated, nil
+}
+
+// DeleteTemplate removes a template. Strategies created from it are not
+// affected.
+func (s *libsqlDB) DeleteTemplate(ctx context.Context, id int) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM strategy_templates WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete template: %w", err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("failed to delete template: %w", sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// InstantiateTemplate creates a private strategy for the user from a
+// template, reading the template and copying it in a single transaction.
+func (s *libsqlDB) InstantiateTemplate(ctx context.Context, templateID int, user models.UserProfile) (*models.Strategy, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	template, err := scanTemplate(tx.QueryRowContext(ctx, `SELECT `+templateColumns+` FROM strategy_templates WHERE id = ?`, templateID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve template: %w", err)
+	}
+
+	strategy := models.Strategy{
+		Name:        template.Name,
+		Description: template.Description,
+		Atlas:       template.Atlas,
+		Duration:    template.Duration,
+		League:      models.LeagueSoftcore,
+	}
+
+	created, err := createStrategyWithTables(ctx, tx, user, strategy, template.Tables)
+	if err != nil {
+		return nil, err
+	}
+
+	return created, tx.Commit()
+}
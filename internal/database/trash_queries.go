@@ -0,0 +1,139 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+// ListTrash paginates the deleted strategies of a user, most recently
+// deleted first.
+func (s *libsqlDB) ListTrash(ctx context.Context, userID string, limit int, offset int) ([]models.StrategyDTO, int, error) {
+	var total int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM strategies WHERE user_id = ? AND deleted_at IS NOT NULL`, userID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("counting deleted strategies: %w", err)
+	}
+
+	query := `
+	SELECT ` + strategyColumns + `
+	FROM strategies
+	WHERE user_id = ? AND deleted_at IS NOT NULL
+	ORDER BY deleted_at DESC, id DESC
+	LIMIT ?
+	OFFSET ?`
+
+	rows, err := s.db.QueryContext(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("listing deleted strategies: %w", err)
+	}
+	defer rows.Close()
+
+	strategies := make([]models.StrategyDTO, 0)
+	for rows.Next() {
+		st, err := scanStrategy(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scanning strategy: %w", err)
+		}
+		strategies = append(strategies, st.DTO())
+	}
+
+	return strategies, total, rows.Err()
+}
+
+// RestoreStrategy takes a strategy out of its owner's trash.
+func (s *libsqlDB) RestoreStrategy(ctx context.Context, userID string, id int) (*models.Strategy, error) {
+	query := `
+	UPDATE strategies
+	SET deleted_at = NULL, version = version + 1, updated_at = unixepoch()
+	WHERE id = ? AND user_id = ? AND deleted_at IS NOT NULL
+	RETURNING ` + strategyColumns
+
+	restored, err := scanStrategy(s.db.QueryRowContext(ctx, query, id, userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore strategy: %w", err)
+	}
+
+	return restored, nil
+}
+
+// PurgeStrategy permanently removes a strategy from its owner's trash.
+func (s *libsqlDB) PurgeStrategy(ctx context.Context, userID string, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists int
+	err = tx.QueryRowContext(ctx, `SELECT 1 FROM strategies WHERE id = ? AND user_id = ? AND deleted_at IS NOT NULL`, id, userID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to purge strategy: %w", err)
+	}
+
+	if err := purgeStrategy(ctx, tx, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PurgeDeletedStrategies permanently removes every strategy deleted before
+// the given unix time and returns how many were removed.
+func (s *libsqlDB) PurgeDeletedStrategies(ctx context.Context, before int64) (int, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id FROM strategies WHERE deleted_at IS NOT NULL AND deleted_at < ?`, before)
+	if err != nil {
+		return 0, fmt.Errorf("listing expired strategies: %w", err)
+	}
+
+	ids := make([]int, 0)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scanning expired strategy: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	// each strategy gets its own transaction so a large purge does not hold
+	// the write lock for long
+	for i, id := range ids {
+		if err := s.purgeExpired(ctx, id, before); err != nil {
+			return i, err
+		}
+	}
+
+	return len(ids), nil
+}
+
+func (s *libsqlDB) purgeExpired(ctx context.Context, id int, before int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// the strategy may have been restored since it was listed
+	var exists int
+	err = tx.QueryRowContext(ctx, `SELECT 1 FROM strategies WHERE id = ? AND deleted_at IS NOT NULL AND deleted_at < ?`, id, before).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to purge strategy: %w", err)
+	}
+
+	if err := purgeStrategy(ctx, tx, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
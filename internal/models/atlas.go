@@ -0,0 +1,75 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// AtlasMechanics lists the league mechanics an atlas setup can invest in.
+var AtlasMechanics = []string{
+	"abyss",
+	"betrayal",
+	"beyond",
+	"blight",
+	"breach",
+	"delirium",
+	"delve",
+	"essence",
+	"expedition",
+	"harbinger",
+	"harvest",
+	"heist",
+	"incursion",
+	"legion",
+	"metamorph",
+	"ritual",
+	"sanctum",
+	"settlers",
+	"strongbox",
+	"torment",
+	"ultimatum",
+}
+
+// AtlasMastery is a mastery node of the atlas tree with its chosen effect.
+type AtlasMastery struct {
+	Node   int `json:"node"`
+	Effect int `json:"effect"`
+}
+
+// AtlasTree is the structured description of an atlas setup. Clients send
+// the tree Code and the Mechanics; Version, Nodes and Masteries are decoded
+// from the code on save.
+type AtlasTree struct {
+	Code      string         `json:"code"`
+	Mechanics []string       `json:"mechanics"`
+	Version   int            `json:"version"`
+	Nodes     []int          `json:"nodes"`
+	Masteries []AtlasMastery `json:"masteries"`
+}
+
+// Scan reads an atlas tree stored as JSON.
+func (a *AtlasTree) Scan(src any) error {
+	switch v := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	case []byte:
+		return json.Unmarshal(v, a)
+	default:
+		return fmt.Errorf("unsupported atlas tree type %T", src)
+	}
+}
+
+// Value stores the atlas tree as JSON.
+func (a *AtlasTree) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
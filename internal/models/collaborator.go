@@ -0,0 +1,25 @@
+package models
+
+const (
+	CollaboratorViewer = "viewer"
+	CollaboratorEditor = "editor"
+
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+)
+
+type Collaborator struct {
+	StrategyID   int    `json:"strategy_id"`
+	StrategyName string `json:"strategy_name,omitempty"`
+	UserID       string `json:"-"`
+	Username     string `json:"username"`
+	Role         string `json:"role"`
+	Status       string `json:"status"`
+	InvitedBy    string `json:"invited_by"`
+	CreatedAt    int64  `json:"created_at"`
+}
+
+type CollaboratorRequest struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
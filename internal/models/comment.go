@@ -0,0 +1,28 @@
+package models
+
+type Comment struct {
+	ID         int       `json:"id"`
+	StrategyID int       `json:"strategy_id"`
+	UserID     string    `json:"-"`
+	Author     string    `json:"author"`
+	ParentID   *int      `json:"parent_id"`
+	RootID     *int      `json:"-"`
+	Body       string    `json:"body"`
+	Deleted    bool      `json:"deleted"`
+	DeletedBy  string    `json:"deleted_by,omitempty"`
+	CreatedAt  int64     `json:"created_at"`
+	UpdatedAt  int64     `json:"updated_at"`
+	Replies    []Comment `json:"replies,omitempty"`
+}
+
+type CommentRequest struct {
+	Body     string `json:"body"`
+	ParentID *int   `json:"parent_id"`
+}
+
+type CommentsDTO struct {
+	Comments []Comment `json:"comments"`
+	Limit    int       `json:"limit"`
+	Offset   int       `json:"offset"`
+	Total    int       `json:"total"`
+}
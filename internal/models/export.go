@@ -0,0 +1,59 @@
+package models
+
+const (
+	ExportFormat  = "exile-profit/strategy"
+	ExportVersion = 1
+)
+
+// ItemRef identifies an item by its stable names instead of a database id.
+type ItemRef struct {
+	Name     string `json:"name"`
+	BaseType string `json:"base_type"`
+	Realm    string `json:"realm,omitempty"`
+}
+
+type ExportItem struct {
+	// Key identifies the item within the document so Pair can refer to it.
+	Key           int      `json:"key"`
+	Item          ItemRef  `json:"item"`
+	Amount        int      `json:"amount"`
+	Role          string   `json:"role"`
+	DropChance    float32  `json:"drop_chance"`
+	Pair          int      `json:"pair,omitempty"`
+	PriceOverride *float64 `json:"price_override,omitempty"`
+}
+
+type ExportTable struct {
+	Type  string       `json:"type"`
+	Title string       `json:"title"`
+	Scale float64      `json:"scale,omitempty"`
+	Items []ExportItem `json:"items"`
+}
+
+type ExportStrategy struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Atlas       string     `json:"atlas"`
+	AtlasTree   *AtlasTree `json:"atlas_tree,omitempty"`
+	Tags        []Tag      `json:"tags,omitempty"`
+	Duration    int64      `json:"duration,omitempty"`
+	League      string     `json:"league,omitempty"`
+}
+
+type StrategyDocument struct {
+	Format   string         `json:"format"`
+	Version  int            `json:"version"`
+	Strategy ExportStrategy `json:"strategy"`
+	Tables   []ExportTable  `json:"tables"`
+}
+
+type UnresolvedItem struct {
+	Table int     `json:"table"`
+	Key   int     `json:"key"`
+	Item  ItemRef `json:"item"`
+}
+
+type ImportResult struct {
+	Strategy   StrategyDTO      `json:"strategy"`
+	Unresolved []UnresolvedItem `json:"unresolved"`
+}
@@ -0,0 +1,18 @@
+package models
+
+type LeaderboardEntry struct {
+	Rank int `json:"rank"`
+	StrategyDTO
+	NetProfit     float64 `json:"net_profit"`
+	ProfitPerHour float64 `json:"profit_per_hour"`
+}
+
+type Leaderboard struct {
+	League    string             `json:"league"`
+	Atlas     string             `json:"atlas,omitempty"`
+	UpdatedAt int64              `json:"updated_at"`
+	Entries   []LeaderboardEntry `json:"entries"`
+	Limit     int                `json:"limit"`
+	Offset    int                `json:"offset"`
+	Total     int                `json:"total"`
+}
@@ -28,9 +28,14 @@ type JWTClaims struct {
 	UserID   string
 	UserName string
 	TokenID  string
+	// FamilyID is shared by the tokens issued from a single login, across
+	// refreshes.
+	FamilyID string
 	jwt.RegisteredClaims
 }
 
+const RoleAdmin = "admin"
+
 type ErrorResponse struct {
 	Error string
 	Code  int
@@ -41,28 +46,127 @@ type TokenPair struct {
 	JWTRefresh string
 }
 
+// SessionClient describes the device a refresh token was issued to.
+type SessionClient struct {
+	UserAgent string
+	IP        string
+}
+
+// Session is a logged in device: the token family of one login, described
+// by the client that last refreshed it. The ID is the family id.
+type Session struct {
+	ID         string `json:"id"`
+	CreatedAt  int64  `json:"created_at"`
+	LastUsedAt int64  `json:"last_used_at"`
+	UserAgent  string `json:"user_agent"`
+	IP         string `json:"ip"`
+	ExpiresAt  int64  `json:"expires_at"`
+	Current    bool   `json:"current"`
+}
+
 type Strategy struct {
-	ID          int    `json:"id"`
-	UserID      string `json:"user_id"`
-	CreatedBy   string `json:"created_by"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Atlas       string `json:"atlas"`
-	Public      bool   `json:"public"`
-	Featured    bool   `json:"featured"`
-	CreatedAt   int64  `json:"created_at"`
-	UpdatedAt   int64  `json:"updated_at"`
+	ID          int        `json:"id"`
+	UserID      string     `json:"user_id"`
+	CreatedBy   string     `json:"created_by"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Atlas       string     `json:"atlas"`
+	AtlasTree   *AtlasTree `json:"atlas_tree"`
+	// Tags are read-only here and managed through the tags endpoint.
+	Tags     []Tag `json:"tags,omitempty"`
+	Duration int64 `json:"duration"`
+	// League is the league profit is computed in unless a request selects
+	// another one.
+	League     string `json:"league"`
+	Public     bool   `json:"public"`
+	Featured   bool   `json:"featured"`
+	ForkedFrom *int   `json:"forked_from"`
+	ForkedBy   string `json:"forked_from_by"`
+	ForkCount  int    `json:"fork_count"`
+	LikeCount  int    `json:"like_count"`
+	ViewCount  int    `json:"view_count"`
+	Version    int    `json:"version"`
+	CreatedAt  int64  `json:"created_at"`
+	UpdatedAt  int64  `json:"updated_at"`
+	DeletedAt  *int64 `json:"deleted_at"`
 }
 
 type StrategyDTO struct {
-	ID          int    `json:"id"`
-	CreatedBy   string `json:"created_by"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Atlas       string `json:"atlas"`
-	Public      bool   `json:"public"`
-	CreatedAt   int64  `json:"created_at"`
-	UpdatedAt   int64  `json:"updated_at"`
+	ID          int        `json:"id"`
+	CreatedBy   string     `json:"created_by"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Atlas       string     `json:"atlas"`
+	AtlasTree   *AtlasTree `json:"atlas_tree"`
+	Tags        []Tag      `json:"tags,omitempty"`
+	Duration    int64      `json:"duration"`
+	League      string     `json:"league"`
+	Public      bool       `json:"public"`
+	ForkedFrom  *int       `json:"forked_from,omitempty"`
+	ForkedBy    string     `json:"forked_from_by,omitempty"`
+	ForkCount   int        `json:"fork_count"`
+	LikeCount   int        `json:"like_count"`
+	ViewCount   int        `json:"view_count"`
+	Version     int        `json:"version"`
+	CreatedAt   int64      `json:"created_at"`
+	UpdatedAt   int64      `json:"updated_at"`
+	DeletedAt   *int64     `json:"deleted_at,omitempty"`
+}
+
+func (s Strategy) DTO() StrategyDTO {
+	return StrategyDTO{
+		ID:          s.ID,
+		CreatedBy:   s.CreatedBy,
+		Name:        s.Name,
+		Description: s.Description,
+		Atlas:       s.Atlas,
+		AtlasTree:   s.AtlasTree,
+		Tags:        s.Tags,
+		Duration:    s.Duration,
+		League:      s.League,
+		Public:      s.Public,
+		ForkedFrom:  s.ForkedFrom,
+		ForkedBy:    s.ForkedBy,
+		ForkCount:   s.ForkCount,
+		LikeCount:   s.LikeCount,
+		ViewCount:   s.ViewCount,
+		Version:     s.Version,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+		DeletedAt:   s.DeletedAt,
+	}
+}
+
+const (
+	StrategySortNewest  = "newest"
+	StrategySortUpdated = "updated"
+	StrategySortPopular = "popular"
+)
+
+// StrategyQuery describes a filtered, paginated strategy listing.
+type StrategyQuery struct {
+	Search string
+	Atlas  string
+	// Tags lists tag names a strategy must all carry to match.
+	Tags      []string
+	CreatedBy string
+	// UserID restricts the listing to a single owner and includes their
+	// private strategies. When empty only public strategies are listed.
+	UserID string
+	Sort   string
+	Limit  int
+	Offset int
+}
+
+type FeaturedStrategy struct {
+	StrategyDTO
+	Position  int    `json:"position"`
+	ExpiresAt *int64 `json:"expires_at"`
+}
+
+type FeatureRequest struct {
+	Position  int    `json:"position"`
+	ExpiresAt *int64 `json:"expires_at"`
 }
 
 type StrategyTable struct {
@@ -70,20 +174,23 @@ type StrategyTable struct {
 	StrategyID int    `json:"strategy_id"`
 	Type       string `json:"type"`
 	Title      string `json:"title"`
+	// Scale multiplies the random drops of the strategy. It only applies
+	// to modifiers tables.
+	Scale   float64 `json:"scale"`
+	Version int     `json:"version"`
 }
 
 type StrategyItem struct {
-	SID        int
+	SID        int     `json:"id"`
 	StrategyID int     `json:"strategy_id"`
 	TableID    int     `json:"table_id"`
-	ItemID     int     `json:"item_id"`
+	ItemID     string  `json:"item_id"`
 	Amount     int     `json:"amount"`
 	Role       string  `json:"role"`
 	DropChance float32 `json:"drop_chance"`
 	Pair       int     `json:"pair"`
-}
-
-type SItem struct {
-	Item
-	StrategyItem
+	// PriceOverride pins the price of the item in profit computations
+	// instead of its market value.
+	PriceOverride *float64 `json:"price_override"`
+	Version       int      `json:"version"`
 }
@@ -0,0 +1,134 @@
+package models
+
+const (
+	ItemRoleInput  = "input"
+	ItemRoleOutput = "output"
+)
+
+const (
+	LeagueSoftcore = "csc"
+	LeagueHardcore = "chc"
+)
+
+// Price sources tell where the price of an item in a profit computation
+// comes from.
+const (
+	PriceSourceOverride = "override"
+	PriceSourceMarket   = "market"
+	PriceSourceNone     = "none"
+)
+
+// PricedStrategyItem is a strategy item joined with its current market price.
+type PricedStrategyItem struct {
+	StrategyItem
+	TableType string
+	Name      string
+	BaseType  string
+	Icon      string
+	Price     float64
+	Priced    bool
+	// PriceSource is one of the PriceSource constants.
+	PriceSource string
+}
+
+type ItemProfit struct {
+	ID          int     `json:"id"`
+	ItemID      string  `json:"item_id"`
+	Name        string  `json:"name"`
+	BaseType    string  `json:"base_type"`
+	Icon        string  `json:"icon"`
+	Role        string  `json:"role"`
+	Amount      int     `json:"amount"`
+	DropChance  float32 `json:"drop_chance"`
+	Price       float64 `json:"price"`
+	Priced      bool    `json:"priced"`
+	PriceSource string  `json:"price_source"`
+	Value       float64 `json:"value"`
+}
+
+type TableProfit struct {
+	TableID     int          `json:"table_id"`
+	Type        string       `json:"type"`
+	Title       string       `json:"title"`
+	InputCost   float64      `json:"input_cost"`
+	OutputValue float64      `json:"output_value"`
+	NetProfit   float64      `json:"net_profit"`
+	Items       []ItemProfit `json:"items"`
+}
+
+type ProfitReport struct {
+	StrategyID    int           `json:"strategy_id"`
+	League        string        `json:"league"`
+	InputCost     float64       `json:"input_cost"`
+	OutputValue   float64       `json:"output_value"`
+	NetProfit     float64       `json:"net_profit"`
+	Duration      int64         `json:"duration"`
+	ProfitPerHour *float64      `json:"profit_per_hour"`
+	Tables        []TableProfit `json:"tables"`
+}
+
+type Percentiles struct {
+	P5  float64 `json:"p5"`
+	P25 float64 `json:"p25"`
+	P50 float64 `json:"p50"`
+	P75 float64 `json:"p75"`
+	P95 float64 `json:"p95"`
+}
+
+type HistogramBin struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int     `json:"count"`
+}
+
+type SimulationReport struct {
+	StrategyID        int            `json:"strategy_id"`
+	League            string         `json:"league"`
+	Runs              int            `json:"runs"`
+	Trials            int            `json:"trials"`
+	Seed              uint64         `json:"seed"`
+	ExpectedProfit    float64        `json:"expected_profit"`
+	Mean              float64        `json:"mean"`
+	StdDev            float64        `json:"std_dev"`
+	Min               float64        `json:"min"`
+	Max               float64        `json:"max"`
+	ProbabilityOfLoss float64        `json:"probability_of_loss"`
+	Percentiles       Percentiles    `json:"percentiles"`
+	Histogram         []HistogramBin `json:"histogram"`
+}
+
+// PricePoint is a single historical price observation from the prices table.
+type PricePoint struct {
+	ItemID     string
+	Price      float64
+	CurrencyID string
+	Timestamp  int64
+}
+
+type ItemContribution struct {
+	ID          int     `json:"id"`
+	ItemID      string  `json:"item_id"`
+	Name        string  `json:"name"`
+	Role        string  `json:"role"`
+	Price       float64 `json:"price"`
+	Priced      bool    `json:"priced"`
+	PriceSource string  `json:"price_source"`
+	Value       float64 `json:"value"`
+}
+
+type BacktestPoint struct {
+	Timestamp   int64              `json:"timestamp"`
+	InputCost   float64            `json:"input_cost"`
+	OutputValue float64            `json:"output_value"`
+	NetProfit   float64            `json:"net_profit"`
+	Items       []ItemContribution `json:"items"`
+}
+
+type BacktestReport struct {
+	StrategyID int             `json:"strategy_id"`
+	League     string          `json:"league"`
+	From       int64           `json:"from"`
+	To         int64           `json:"to"`
+	Step       int64           `json:"step"`
+	Points     []BacktestPoint `json:"points"`
+}
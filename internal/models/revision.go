@@ -0,0 +1,61 @@
+package models
+
+// StrategySnapshot is the content of a strategy at a point in time.
+type StrategySnapshot struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Atlas       string          `json:"atlas"`
+	AtlasTree   *AtlasTree      `json:"atlas_tree,omitempty"`
+	Duration    int64           `json:"duration"`
+	League      string          `json:"league,omitempty"`
+	Tables      []SnapshotTable `json:"tables"`
+}
+
+type SnapshotTable struct {
+	StrategyTable
+	Items []StrategyItem `json:"items"`
+}
+
+type StrategyRevision struct {
+	ID         int               `json:"id"`
+	StrategyID int               `json:"strategy_id"`
+	Revision   int               `json:"revision"`
+	CreatedBy  string            `json:"created_by"`
+	CreatedAt  int64             `json:"created_at"`
+	Snapshot   *StrategySnapshot `json:"snapshot,omitempty"`
+}
+
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "modified"
+)
+
+type FieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+type ItemChange struct {
+	ID     int           `json:"id"`
+	ItemID string        `json:"item_id"`
+	Change string        `json:"change"`
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+type TableChange struct {
+	TableID int           `json:"table_id"`
+	Title   string        `json:"title"`
+	Change  string        `json:"change"`
+	Fields  []FieldChange `json:"fields,omitempty"`
+	Items   []ItemChange  `json:"items,omitempty"`
+}
+
+type RevisionDiff struct {
+	StrategyID int           `json:"strategy_id"`
+	From       int           `json:"from"`
+	To         int           `json:"to"`
+	Fields     []FieldChange `json:"fields"`
+	Tables     []TableChange `json:"tables"`
+}
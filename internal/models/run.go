@@ -0,0 +1,75 @@
+package models
+
+// StrategyRun is a completed run of a strategy recorded by a user. Duration
+// is in seconds.
+type StrategyRun struct {
+	ID         int       `json:"id"`
+	StrategyID int       `json:"strategy_id"`
+	UserID     string    `json:"-"`
+	Username   string    `json:"username"`
+	League     string    `json:"league"`
+	Duration   int64     `json:"duration"`
+	Notes      string    `json:"notes"`
+	Items      []RunItem `json:"items"`
+	CreatedAt  int64     `json:"created_at"`
+}
+
+// RunItem is the quantity of a strategy item consumed (inputs) or obtained
+// (outputs) during a run.
+type RunItem struct {
+	StrategyItemID int `json:"strategy_item_id"`
+	Quantity       int `json:"quantity"`
+}
+
+type RunRequest struct {
+	League   string    `json:"league"`
+	Duration int64     `json:"duration"`
+	Notes    string    `json:"notes"`
+	Items    []RunItem `json:"items"`
+}
+
+type RunsDTO struct {
+	Runs   []StrategyRun `json:"runs"`
+	Limit  int           `json:"limit"`
+	Offset int           `json:"offset"`
+	Total  int           `json:"total"`
+}
+
+// RunItemReport compares what a strategy declares for an item with what was
+// observed across the recorded runs. Quantities are per run.
+type RunItemReport struct {
+	ID                 int      `json:"id"`
+	ItemID             string   `json:"item_id"`
+	Name               string   `json:"name"`
+	Role               string   `json:"role"`
+	Amount             int      `json:"amount"`
+	DropChance         float32  `json:"drop_chance"`
+	ObservedDropChance *float64 `json:"observed_drop_chance"`
+	ExpectedQuantity   float64  `json:"expected_quantity"`
+	ObservedQuantity   float64  `json:"observed_quantity"`
+	Price              float64  `json:"price"`
+	Priced             bool     `json:"priced"`
+	ExpectedValue      float64  `json:"expected_value"`
+	ObservedValue      float64  `json:"observed_value"`
+}
+
+type RunReport struct {
+	StrategyID            int             `json:"strategy_id"`
+	League                string          `json:"league"`
+	Runs                  int             `json:"runs"`
+	TotalDuration         int64           `json:"total_duration"`
+	AverageDuration       float64         `json:"average_duration"`
+	ExpectedProfit        float64         `json:"expected_profit"`
+	ObservedProfit        float64         `json:"observed_profit"`
+	ExpectedProfitPerHour float64         `json:"expected_profit_per_hour"`
+	ObservedProfitPerHour float64         `json:"observed_profit_per_hour"`
+	Items                 []RunItemReport `json:"items"`
+}
+
+// RunTotals aggregates the recorded runs of a strategy in one league.
+// Quantities are summed per strategy item ID.
+type RunTotals struct {
+	Runs       int
+	Duration   int64
+	Quantities map[int]int
+}
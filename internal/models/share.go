@@ -0,0 +1,14 @@
+package models
+
+type ShareLink struct {
+	ID         int    `json:"id"`
+	StrategyID int    `json:"strategy_id"`
+	Token      string `json:"token"`
+	CreatedAt  int64  `json:"created_at"`
+	ExpiresAt  *int64 `json:"expires_at"`
+	RevokedAt  *int64 `json:"revoked_at"`
+}
+
+type ShareLinkRequest struct {
+	ExpiresAt *int64 `json:"expires_at"`
+}
@@ -0,0 +1,73 @@
+package models
+
+const (
+	TableTypeInputs     = "inputs"
+	TableTypeGuaranteed = "guaranteed"
+	TableTypeDrops      = "drops"
+	TableTypeEitherOr   = "either_or"
+	TableTypeModifiers  = "modifiers"
+)
+
+// TableKind describes how the items of a table of a given type are valued.
+type TableKind struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Role is the role every item of the table must have.
+	Role string `json:"role"`
+	// Random tables weight their items by drop chance and are scaled by
+	// modifiers tables.
+	Random bool `json:"random"`
+	// Pairs tells whether items may be linked into either-or groups.
+	Pairs bool `json:"pairs"`
+	// Scales tells whether the table carries a scale for random tables.
+	Scales bool `json:"scales"`
+}
+
+var TableKinds = []TableKind{
+	{
+		Type:        TableTypeInputs,
+		Name:        "Inputs",
+		Description: "Items consumed by every run, such as maps and fragments.",
+		Role:        ItemRoleInput,
+	},
+	{
+		Type:        TableTypeGuaranteed,
+		Name:        "Guaranteed outputs",
+		Description: "Items obtained on every run regardless of luck.",
+		Role:        ItemRoleOutput,
+	},
+	{
+		Type:        TableTypeDrops,
+		Name:        "Random drops",
+		Description: "Items that drop independently with their drop chance.",
+		Role:        ItemRoleOutput,
+		Random:      true,
+	},
+	{
+		Type:        TableTypeEitherOr,
+		Name:        "Either-or",
+		Description: "Paired items of which at most one drops per run.",
+		Role:        ItemRoleOutput,
+		Random:      true,
+		Pairs:       true,
+	},
+	{
+		Type:        TableTypeModifiers,
+		Name:        "Modifiers",
+		Description: "Scarabs and waystone modifiers consumed by every run that scale all random drops.",
+		Role:        ItemRoleInput,
+		Scales:      true,
+	},
+}
+
+// LookupTableKind returns the kind of the given table type.
+func LookupTableKind(tableType string) (TableKind, bool) {
+	for _, k := range TableKinds {
+		if k.Type == tableType {
+			return k, true
+		}
+	}
+
+	return TableKind{}, false
+}
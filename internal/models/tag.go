@@ -0,0 +1,21 @@
+package models
+
+const (
+	TagCategoryMechanic = "mechanic"
+	TagCategoryMap      = "map"
+	TagCategoryLeague   = "league"
+)
+
+var TagCategories = []string{TagCategoryMechanic, TagCategoryMap, TagCategoryLeague}
+
+type Tag struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+}
+
+// TagSuggestion is a tag offered by autocomplete together with the number
+// of public strategies using it.
+type TagSuggestion struct {
+	Tag
+	Count int `json:"count"`
+}
@@ -0,0 +1,16 @@
+package models
+
+// StrategyTemplate is an admin-managed strategy skeleton. Its items are
+// placeholders the user replaces after instantiating the template; their
+// ids only link Pair references within the template.
+type StrategyTemplate struct {
+	ID          int             `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Atlas       string          `json:"atlas"`
+	Duration    int64           `json:"duration"`
+	Tables      []SnapshotTable `json:"tables"`
+	CreatedBy   string          `json:"created_by"`
+	CreatedAt   int64           `json:"created_at"`
+	UpdatedAt   int64           `json:"updated_at"`
+}
@@ -0,0 +1,111 @@
+package profit
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+// History indexes historical price observations per item.
+type History map[string][]models.PricePoint
+
+// NewHistory groups price points by item, keeping them sorted by time.
+func NewHistory(points []models.PricePoint) History {
+	h := make(History)
+	for _, p := range points {
+		h[p.ItemID] = append(h[p.ItemID], p)
+	}
+
+	for _, series := range h {
+		sort.Slice(series, func(i, j int) bool { return series[i].Timestamp < series[j].Timestamp })
+	}
+
+	return h
+}
+
+// latest returns the last observation of the item at or before ts.
+func (h History) latest(itemID string, ts int64) (models.PricePoint, bool) {
+	series := h[itemID]
+	i := sort.Search(len(series), func(i int) bool { return series[i].Timestamp > ts })
+	if i == 0 {
+		return models.PricePoint{}, false
+	}
+
+	return series[i-1], true
+}
+
+// Value returns the price of the item at ts converted through its quote
+// currency. Currencies without any history are treated as the base
+// currency; a currency whose history only starts after ts cannot convert
+// the price, which is then unpriced like a missing observation.
+func (h History) Value(itemID string, ts int64) (float64, bool) {
+	p, ok := h.latest(itemID, ts)
+	if !ok {
+		return 0, false
+	}
+
+	if p.CurrencyID == "" || p.CurrencyID == itemID {
+		return p.Price, true
+	}
+
+	if _, known := h[p.CurrencyID]; !known {
+		return p.Price, true
+	}
+
+	rate, ok := h.latest(p.CurrencyID, ts)
+	if !ok {
+		return 0, false
+	}
+
+	return p.Price * rate.Price, true
+}
+
+// Backtest re-evaluates the strategy at every step between from and to using
+// the prices known at that point in time. Overridden prices stay fixed.
+func Backtest(tables []models.StrategyTable, items []models.PricedStrategyItem, history History, from, to time.Time, step time.Duration) []models.BacktestPoint {
+	points := make([]models.BacktestPoint, 0)
+	valuation := newValuation(tables, items)
+
+	for t := from; !t.After(to); t = t.Add(step) {
+		ts := t.Unix()
+		point := models.BacktestPoint{
+			Timestamp: ts,
+			Items:     make([]models.ItemContribution, 0, len(items)),
+		}
+
+		for _, item := range items {
+			if item.PriceSource != models.PriceSourceOverride {
+				item.Price, item.Priced = history.Value(item.ItemID, ts)
+				item.PriceSource = models.PriceSourceMarket
+				if !item.Priced {
+					item.PriceSource = models.PriceSourceNone
+				}
+			}
+
+			value := valuation.value(item)
+
+			if item.Role == models.ItemRoleInput {
+				point.InputCost += value
+			} else {
+				point.OutputValue += value
+			}
+
+			point.Items = append(point.Items, models.ItemContribution{
+				ID:          item.SID,
+				ItemID:      item.ItemID,
+				Name:        item.Name,
+				Role:        item.Role,
+				Price:       item.Price,
+				Priced:      item.Priced,
+				PriceSource: item.PriceSource,
+				Value:       value,
+			})
+		}
+
+		point.NetProfit = point.OutputValue - point.InputCost
+		points = append(points, point)
+	}
+
+	return points
+}
@@ -0,0 +1,105 @@
+package profit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+func TestHistoryValue(t *testing.T) {
+	history := NewHistory([]models.PricePoint{
+		// out of order on purpose, NewHistory sorts the series
+		{ItemID: "mirror", Price: 2, CurrencyID: "divine", Timestamp: 300},
+		{ItemID: "mirror", Price: 1, CurrencyID: "divine", Timestamp: 100},
+		{ItemID: "divine", Price: 150, Timestamp: 200},
+		{ItemID: "divine", Price: 200, Timestamp: 400},
+		{ItemID: "scarab", Price: 3, Timestamp: 100},
+		{ItemID: "fossil", Price: 4, CurrencyID: "chaos", Timestamp: 100},
+		{ItemID: "chaos", Price: 1, CurrencyID: "chaos", Timestamp: 100},
+	})
+
+	tests := []struct {
+		name   string
+		itemID string
+		ts     int64
+		want   float64
+		priced bool
+	}{
+		{"before the first observation", "scarab", 50, 0, false},
+		{"unknown item", "orb", 500, 0, false},
+		{"base currency price", "scarab", 150, 3, true},
+		{"currency quoted in itself", "chaos", 100, 1, true},
+		{"currency without history is the base", "fossil", 100, 4, true},
+		{"no rate yet", "mirror", 150, 0, false},
+		{"converted with the latest rate", "mirror", 250, 150, true},
+		{"latest price and rate", "mirror", 300, 300, true},
+		{"rate changes after the price", "mirror", 500, 400, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, priced := history.Value(tt.itemID, tt.ts)
+			if priced != tt.priced || !approxEqual(got, tt.want) {
+				t.Errorf("Value(%q, %d) = %v, %v, want %v, %v", tt.itemID, tt.ts, got, priced, tt.want, tt.priced)
+			}
+		})
+	}
+}
+
+func TestBacktest(t *testing.T) {
+	tables := []models.StrategyTable{
+		{ID: 1, Type: models.TableTypeInputs},
+		{ID: 3, Type: models.TableTypeDrops},
+	}
+
+	input := item(1, 1, models.TableTypeInputs, 1, 1, 999)
+	input.ItemID = "map"
+
+	drop := item(2, 3, models.TableTypeDrops, 2, 0.5, 999)
+	drop.ItemID = "orb"
+
+	override := 7.0
+	fixed := item(3, 3, models.TableTypeDrops, 1, 1, override)
+	fixed.ItemID = "orb"
+	fixed.PriceOverride = &override
+	fixed.PriceSource = models.PriceSourceOverride
+
+	history := NewHistory([]models.PricePoint{
+		{ItemID: "map", Price: 5, Timestamp: 0},
+		{ItemID: "orb", Price: 10, Timestamp: 3600},
+		{ItemID: "orb", Price: 20, Timestamp: 7200},
+	})
+
+	from := time.Unix(0, 0)
+	points := Backtest(tables, []models.PricedStrategyItem{input, drop, fixed}, history, from, from.Add(2*time.Hour), time.Hour)
+
+	want := []struct {
+		net    float64
+		source string
+	}{
+		// the orb has no price yet and only the override counts
+		{net: 7 - 5, source: models.PriceSourceNone},
+		{net: 10 + 7 - 5, source: models.PriceSourceMarket},
+		{net: 20 + 7 - 5, source: models.PriceSourceMarket},
+	}
+
+	if len(points) != len(want) {
+		t.Fatalf("Backtest() returned %d points, want %d", len(points), len(want))
+	}
+
+	for i, p := range points {
+		if p.Timestamp != from.Add(time.Duration(i)*time.Hour).Unix() {
+			t.Errorf("point %d: Timestamp = %d", i, p.Timestamp)
+		}
+		if !approxEqual(p.NetProfit, want[i].net) {
+			t.Errorf("point %d: NetProfit = %v, want %v", i, p.NetProfit, want[i].net)
+		}
+		if got := p.Items[1].PriceSource; got != want[i].source {
+			t.Errorf("point %d: drop PriceSource = %q, want %q", i, got, want[i].source)
+		}
+		if got := p.Items[2]; got.PriceSource != models.PriceSourceOverride || !approxEqual(got.Price, override) {
+			t.Errorf("point %d: override item = %+v, want a fixed price of %v", i, got, override)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Package profit computes the expected value of running a strategy
+// from its tables, items and item prices.
+package profit
+
+import "github.com/Vyary/api/internal/models"
+
+// Scale returns the combined multiplier that the modifiers tables apply to
+// random drops. Unset scales count as 1.
+func Scale(tables []models.StrategyTable) float64 {
+	scale := 1.0
+	for _, t := range tables {
+		if t.Type == models.TableTypeModifiers && t.Scale > 0 {
+			scale *= t.Scale
+		}
+	}
+
+	return scale
+}
+
+// random reports whether the item only drops with its drop chance. Items of
+// tables without a known kind fall back to their role.
+func random(item models.PricedStrategyItem) bool {
+	if kind, ok := models.LookupTableKind(item.TableType); ok {
+		return kind.Random
+	}
+
+	return item.Role != models.ItemRoleInput
+}
+
+// randomDrops returns the outputs that only drop with their drop chance.
+func randomDrops(items []models.PricedStrategyItem) []models.PricedStrategyItem {
+	drops := make([]models.PricedStrategyItem, 0, len(items))
+	for _, item := range items {
+		if item.Role != models.ItemRoleInput && random(item) {
+			drops = append(drops, item)
+		}
+	}
+
+	return drops
+}
+
+// tableItems keeps the items whose table is part of tables.
+func tableItems(tables []models.StrategyTable, items []models.PricedStrategyItem) []models.PricedStrategyItem {
+	known := make(map[int]bool, len(tables))
+	for _, t := range tables {
+		known[t.ID] = true
+	}
+
+	kept := make([]models.PricedStrategyItem, 0, len(items))
+	for _, item := range items {
+		if known[item.TableID] {
+			kept = append(kept, item)
+		}
+	}
+
+	return kept
+}
+
+// valuation values the items of a strategy: random drops depend on the
+// modifiers scale and, for either-or groups, on the other items of their
+// group.
+type valuation struct {
+	scale float64
+	// shares holds the part of the drop chance that counts for items of
+	// either-or groups whose chances add up to more than 1.
+	shares map[int]float64
+}
+
+// newValuation prepares the valuation of items. At most one item of an
+// either-or group drops per run, so group chances above 1 are normalised
+// the same way Simulate samples them.
+func newValuation(tables []models.StrategyTable, items []models.PricedStrategyItem) valuation {
+	v := valuation{scale: Scale(tables), shares: make(map[int]float64)}
+
+	for _, g := range pairGroups(randomDrops(items)) {
+		total := g.chance()
+		if !g.either || total <= 1 {
+			continue
+		}
+
+		for _, item := range g.items {
+			v.shares[item.SID] = 1 / total
+		}
+	}
+
+	return v
+}
+
+// quantity returns how many of the item a single run consumes or obtains
+// on average. Random drops are weighted by their drop chance and scaled by
+// the modifiers tables, everything else counts in full.
+func (v valuation) quantity(item models.PricedStrategyItem) float64 {
+	quantity := float64(item.Amount)
+	if !random(item) {
+		return quantity
+	}
+
+	chance := float64(item.DropChance)
+	if share, ok := v.shares[item.SID]; ok {
+		chance *= share
+	}
+
+	return quantity * chance * v.scale
+}
+
+// value returns the per-run value of a single strategy item.
+func (v valuation) value(item models.PricedStrategyItem) float64 {
+	return v.quantity(item) * item.Price
+}
+
+// Calculate builds the expected profit report of a single run. Items whose
+// table is not part of tables are ignored. Items of an either-or group are
+// valued by their chance of being the one that drops.
+func Calculate(tables []models.StrategyTable, items []models.PricedStrategyItem) models.ProfitReport {
+	var report models.ProfitReport
+
+	items = tableItems(tables, items)
+	valuation := newValuation(tables, items)
+
+	index := make(map[int]int, len(tables))
+	report.Tables = make([]models.TableProfit, len(tables))
+
+	for i, t := range tables {
+		index[t.ID] = i
+		report.Tables[i] = models.TableProfit{
+			TableID: t.ID,
+			Type:    t.Type,
+			Title:   t.Title,
+			Items:   make([]models.ItemProfit, 0),
+		}
+	}
+
+	for _, item := range items {
+		table := &report.Tables[index[item.TableID]]
+		value := valuation.value(item)
+
+		if item.Role == models.ItemRoleInput {
+			table.InputCost += value
+		} else {
+			table.OutputValue += value
+		}
+
+		table.Items = append(table.Items, models.ItemProfit{
+			ID:          item.SID,
+			ItemID:      item.ItemID,
+			Name:        item.Name,
+			BaseType:    item.BaseType,
+			Icon:        item.Icon,
+			Role:        item.Role,
+			Amount:      item.Amount,
+			DropChance:  item.DropChance,
+			Price:       item.Price,
+			Priced:      item.Priced,
+			PriceSource: item.PriceSource,
+			Value:       value,
+		})
+	}
+
+	for i := range report.Tables {
+		table := &report.Tables[i]
+		table.NetProfit = table.OutputValue - table.InputCost
+
+		report.InputCost += table.InputCost
+		report.OutputValue += table.OutputValue
+	}
+
+	report.NetProfit = report.OutputValue - report.InputCost
+
+	return report
+}
+
+// PerHour converts the profit of a single run lasting duration seconds into
+// profit per hour. It returns false when the duration is unknown.
+func PerHour(profit float64, duration int64) (float64, bool) {
+	if duration <= 0 {
+		return 0, false
+	}
+
+	return profit * 3600 / float64(duration), true
+}
@@ -0,0 +1,212 @@
+package profit
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+const epsilon = 1e-9
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < epsilon
+}
+
+// item builds a priced strategy item of a table with the role its kind
+// requires.
+func item(sid, tableID int, tableType string, amount int, chance float32, price float64) models.PricedStrategyItem {
+	role := models.ItemRoleOutput
+	if kind, ok := models.LookupTableKind(tableType); ok {
+		role = kind.Role
+	}
+
+	return models.PricedStrategyItem{
+		StrategyItem: models.StrategyItem{
+			SID:        sid,
+			TableID:    tableID,
+			ItemID:     "item",
+			Amount:     amount,
+			Role:       role,
+			DropChance: chance,
+		},
+		TableType:   tableType,
+		Price:       price,
+		Priced:      true,
+		PriceSource: models.PriceSourceMarket,
+	}
+}
+
+func paired(i models.PricedStrategyItem, pair int) models.PricedStrategyItem {
+	i.Pair = pair
+	return i
+}
+
+func TestScale(t *testing.T) {
+	tests := []struct {
+		name   string
+		tables []models.StrategyTable
+		want   float64
+	}{
+		{"no tables", nil, 1},
+		{"no modifiers", []models.StrategyTable{{Type: models.TableTypeDrops, Scale: 3}}, 1},
+		{"single modifiers", []models.StrategyTable{{Type: models.TableTypeModifiers, Scale: 1.5}}, 1.5},
+		{"modifiers multiply", []models.StrategyTable{{Type: models.TableTypeModifiers, Scale: 2}, {Type: models.TableTypeModifiers, Scale: 1.5}}, 3},
+		{"unset scale counts as 1", []models.StrategyTable{{Type: models.TableTypeModifiers}, {Type: models.TableTypeModifiers, Scale: 2}}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Scale(tt.tables); !approxEqual(got, tt.want) {
+				t.Errorf("Scale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	inputs := models.StrategyTable{ID: 1, Type: models.TableTypeInputs}
+	guaranteed := models.StrategyTable{ID: 2, Type: models.TableTypeGuaranteed}
+	drops := models.StrategyTable{ID: 3, Type: models.TableTypeDrops}
+	eitherOr := models.StrategyTable{ID: 4, Type: models.TableTypeEitherOr}
+	modifiers := models.StrategyTable{ID: 5, Type: models.TableTypeModifiers, Scale: 2}
+
+	tests := []struct {
+		name   string
+		tables []models.StrategyTable
+		items  []models.PricedStrategyItem
+		input  float64
+		output float64
+	}{
+		{
+			name:   "inputs and guaranteed outputs count in full",
+			tables: []models.StrategyTable{inputs, guaranteed},
+			items: []models.PricedStrategyItem{
+				item(1, 1, models.TableTypeInputs, 2, 0.5, 10),
+				item(2, 2, models.TableTypeGuaranteed, 3, 0.25, 4),
+			},
+			input:  20,
+			output: 12,
+		},
+		{
+			name:   "drops are weighted by chance",
+			tables: []models.StrategyTable{drops},
+			items: []models.PricedStrategyItem{
+				item(1, 3, models.TableTypeDrops, 2, 0.25, 100),
+				item(2, 3, models.TableTypeDrops, 1, 0.5, 10),
+			},
+			output: 55,
+		},
+		{
+			name:   "modifiers scale random drops only",
+			tables: []models.StrategyTable{guaranteed, drops, modifiers},
+			items: []models.PricedStrategyItem{
+				item(1, 2, models.TableTypeGuaranteed, 1, 1, 10),
+				item(2, 3, models.TableTypeDrops, 1, 0.25, 100),
+				item(3, 5, models.TableTypeModifiers, 1, 1, 5),
+			},
+			input:  5,
+			output: 10 + 0.25*100*2,
+		},
+		{
+			name:   "either-or group within one drop",
+			tables: []models.StrategyTable{eitherOr},
+			items: []models.PricedStrategyItem{
+				item(1, 4, models.TableTypeEitherOr, 1, 0.25, 100),
+				paired(item(2, 4, models.TableTypeEitherOr, 1, 0.5, 10), 1),
+			},
+			output: 0.25*100 + 0.5*10,
+		},
+		{
+			name:   "either-or group above one drop is normalised",
+			tables: []models.StrategyTable{eitherOr},
+			items: []models.PricedStrategyItem{
+				item(1, 4, models.TableTypeEitherOr, 1, 0.75, 100),
+				paired(item(2, 4, models.TableTypeEitherOr, 1, 0.75, 20), 1),
+				paired(item(3, 4, models.TableTypeEitherOr, 1, 0.5, 40), 2),
+			},
+			output: (0.75*100 + 0.75*20 + 0.5*40) / 2,
+		},
+		{
+			name:   "either-or group is scaled after normalising",
+			tables: []models.StrategyTable{eitherOr, modifiers},
+			items: []models.PricedStrategyItem{
+				item(1, 4, models.TableTypeEitherOr, 1, 1, 100),
+				paired(item(2, 4, models.TableTypeEitherOr, 1, 1, 20), 1),
+			},
+			output: (100 + 20) / 2 * 2,
+		},
+		{
+			name:   "items of unknown tables are ignored",
+			tables: []models.StrategyTable{drops},
+			items: []models.PricedStrategyItem{
+				item(1, 3, models.TableTypeDrops, 1, 0.5, 10),
+				item(2, 99, models.TableTypeDrops, 1, 0.5, 1000),
+			},
+			output: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Calculate(tt.tables, tt.items)
+
+			if !approxEqual(report.InputCost, tt.input) {
+				t.Errorf("InputCost = %v, want %v", report.InputCost, tt.input)
+			}
+			if !approxEqual(report.OutputValue, tt.output) {
+				t.Errorf("OutputValue = %v, want %v", report.OutputValue, tt.output)
+			}
+			if !approxEqual(report.NetProfit, tt.output-tt.input) {
+				t.Errorf("NetProfit = %v, want %v", report.NetProfit, tt.output-tt.input)
+			}
+
+			var tables float64
+			for _, table := range report.Tables {
+				tables += table.NetProfit
+			}
+			if !approxEqual(tables, report.NetProfit) {
+				t.Errorf("table profits add up to %v, want %v", tables, report.NetProfit)
+			}
+		})
+	}
+}
+
+func TestCalculateMatchesSimulationExpectation(t *testing.T) {
+	tables := []models.StrategyTable{{ID: 4, Type: models.TableTypeEitherOr}}
+	items := []models.PricedStrategyItem{
+		item(1, 4, models.TableTypeEitherOr, 1, 0.75, 100),
+		paired(item(2, 4, models.TableTypeEitherOr, 1, 0.75, 20), 1),
+	}
+
+	report := Calculate(tables, items)
+	sim := Simulate(tables, items, SimulationConfig{Runs: 1, Trials: 1, Bins: 1, Seed: 1})
+
+	if !approxEqual(report.NetProfit, sim.ExpectedProfit) {
+		t.Errorf("Calculate() = %v, Simulate() expects %v", report.NetProfit, sim.ExpectedProfit)
+	}
+}
+
+func TestPerHour(t *testing.T) {
+	tests := []struct {
+		name     string
+		profit   float64
+		duration int64
+		want     float64
+		ok       bool
+	}{
+		{"unknown duration", 100, 0, 0, false},
+		{"negative duration", 100, -60, 0, false},
+		{"ten minutes", 100, 600, 600, true},
+		{"loss", -50, 1800, -100, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := PerHour(tt.profit, tt.duration)
+			if ok != tt.ok || !approxEqual(got, tt.want) {
+				t.Errorf("PerHour() = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package profit
+
+import "github.com/Vyary/api/internal/models"
+
+// CompareRuns contrasts the declared amounts and drop chances of a strategy
+// with the quantities observed across its recorded runs, at current prices.
+func CompareRuns(tables []models.StrategyTable, items []models.PricedStrategyItem, totals models.RunTotals) models.RunReport {
+	valuation := newValuation(tables, items)
+
+	report := models.RunReport{
+		Runs:          totals.Runs,
+		TotalDuration: totals.Duration,
+		Items:         make([]models.RunItemReport, 0, len(items)),
+	}
+
+	if totals.Runs > 0 {
+		report.AverageDuration = float64(totals.Duration) / float64(totals.Runs)
+	}
+
+	for _, item := range items {
+		ir := models.RunItemReport{
+			ID:               item.SID,
+			ItemID:           item.ItemID,
+			Name:             item.Name,
+			Role:             item.Role,
+			Amount:           item.Amount,
+			DropChance:       item.DropChance,
+			ExpectedQuantity: valuation.quantity(item),
+			Price:            item.Price,
+			Priced:           item.Priced,
+			ExpectedValue:    valuation.value(item),
+		}
+
+		if totals.Runs > 0 {
+			quantity := float64(totals.Quantities[item.SID])
+			ir.ObservedQuantity = quantity / float64(totals.Runs)
+
+			// modifiers are divided out so the chance is comparable to
+			// the declared one
+			if random(item) && item.Amount > 0 {
+				chance := quantity / (float64(totals.Runs*item.Amount) * valuation.scale)
+				ir.ObservedDropChance = &chance
+			}
+		}
+
+		ir.ObservedValue = ir.ObservedQuantity * item.Price
+
+		if item.Role == models.ItemRoleInput {
+			report.ExpectedProfit -= ir.ExpectedValue
+			report.ObservedProfit -= ir.ObservedValue
+		} else {
+			report.ExpectedProfit += ir.ExpectedValue
+			report.ObservedProfit += ir.ObservedValue
+		}
+
+		report.Items = append(report.Items, ir)
+	}
+
+	if report.AverageDuration > 0 {
+		report.ExpectedProfitPerHour = report.ExpectedProfit * 3600 / report.AverageDuration
+		report.ObservedProfitPerHour = report.ObservedProfit * 3600 / report.AverageDuration
+	}
+
+	return report
+}
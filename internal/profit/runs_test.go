@@ -0,0 +1,126 @@
+package profit
+
+import (
+	"testing"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+func chance(c float64) *float64 {
+	return &c
+}
+
+func TestCompareRuns(t *testing.T) {
+	tables := []models.StrategyTable{
+		{ID: 1, Type: models.TableTypeInputs},
+		{ID: 3, Type: models.TableTypeDrops},
+		{ID: 4, Type: models.TableTypeModifiers, Scale: 2},
+	}
+
+	items := []models.PricedStrategyItem{
+		item(1, 1, models.TableTypeInputs, 2, 1, 10),
+		item(2, 3, models.TableTypeDrops, 1, 0.5, 100),
+		item(5, 4, models.TableTypeModifiers, 1, 1, 5),
+	}
+
+	tests := []struct {
+		name           string
+		totals         models.RunTotals
+		average        float64
+		observedProfit float64
+		expectedHour   float64
+		observedHour   float64
+		observedQty    []float64
+		observedChance *float64
+	}{
+		{
+			name:           "no runs",
+			totals:         models.RunTotals{},
+			observedQty:    []float64{0, 0, 0},
+			observedProfit: 0,
+		},
+		{
+			name: "observed runs",
+			totals: models.RunTotals{
+				Runs:       4,
+				Duration:   1200,
+				Quantities: map[int]int{1: 8, 2: 6, 5: 4},
+			},
+			average:        300,
+			observedQty:    []float64{2, 1.5, 1},
+			observedProfit: 125,
+			expectedHour:   900,
+			observedHour:   1500,
+			// modifiers are divided out: 6 drops over 4 runs at scale 2
+			observedChance: chance(0.75),
+		},
+		{
+			name: "item never observed",
+			totals: models.RunTotals{
+				Runs:       2,
+				Duration:   3600,
+				Quantities: map[int]int{1: 4, 5: 2},
+			},
+			average:        1800,
+			observedQty:    []float64{2, 0, 1},
+			observedProfit: -25,
+			expectedHour:   150,
+			observedHour:   -50,
+			observedChance: chance(0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := CompareRuns(tables, items, tt.totals)
+
+			if report.Runs != tt.totals.Runs || report.TotalDuration != tt.totals.Duration {
+				t.Errorf("Runs, TotalDuration = %d, %d, want %d, %d", report.Runs, report.TotalDuration, tt.totals.Runs, tt.totals.Duration)
+			}
+			if !approxEqual(report.AverageDuration, tt.average) {
+				t.Errorf("AverageDuration = %v, want %v", report.AverageDuration, tt.average)
+			}
+			// one drop at 0.5 chance scaled by 2, minus inputs and modifiers
+			if !approxEqual(report.ExpectedProfit, 75) {
+				t.Errorf("ExpectedProfit = %v, want 75", report.ExpectedProfit)
+			}
+			if !approxEqual(report.ObservedProfit, tt.observedProfit) {
+				t.Errorf("ObservedProfit = %v, want %v", report.ObservedProfit, tt.observedProfit)
+			}
+			if !approxEqual(report.ExpectedProfitPerHour, tt.expectedHour) || !approxEqual(report.ObservedProfitPerHour, tt.observedHour) {
+				t.Errorf("per hour = %v, %v, want %v, %v", report.ExpectedProfitPerHour, report.ObservedProfitPerHour, tt.expectedHour, tt.observedHour)
+			}
+
+			if len(report.Items) != len(items) {
+				t.Fatalf("len(Items) = %d, want %d", len(report.Items), len(items))
+			}
+
+			for i, ir := range report.Items {
+				if !approxEqual(ir.ObservedQuantity, tt.observedQty[i]) {
+					t.Errorf("Items[%d].ObservedQuantity = %v, want %v", i, ir.ObservedQuantity, tt.observedQty[i])
+				}
+				if !approxEqual(ir.ObservedValue, tt.observedQty[i]*items[i].Price) {
+					t.Errorf("Items[%d].ObservedValue = %v, want %v", i, ir.ObservedValue, tt.observedQty[i]*items[i].Price)
+				}
+			}
+
+			if !approxEqual(report.Items[1].ExpectedQuantity, 1) {
+				t.Errorf("drop ExpectedQuantity = %v, want 1", report.Items[1].ExpectedQuantity)
+			}
+
+			for i, ir := range report.Items {
+				if i != 1 && ir.ObservedDropChance != nil {
+					t.Errorf("Items[%d].ObservedDropChance = %v, want nil for guaranteed items", i, *ir.ObservedDropChance)
+				}
+			}
+
+			got := report.Items[1].ObservedDropChance
+			switch {
+			case tt.observedChance == nil && got != nil:
+				t.Errorf("ObservedDropChance = %v, want nil", *got)
+			case tt.observedChance != nil && (got == nil || !approxEqual(*got, *tt.observedChance)):
+				t.Errorf("ObservedDropChance = %v, want %v", got, *tt.observedChance)
+			}
+		})
+	}
+}
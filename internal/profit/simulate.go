@@ -0,0 +1,237 @@
+package profit
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+type SimulationConfig struct {
+	// Runs is the number of strategy runs summed into a single trial.
+	Runs int
+	// Trials is the number of sampled outcomes the distribution is built from.
+	Trials int
+	Bins   int
+	Seed   uint64
+}
+
+// outcome is a set of output items of which at most one drops per run.
+// Items without a pair form a group of their own.
+type outcome struct {
+	items  []models.PricedStrategyItem
+	either bool
+}
+
+// Simulate samples the profit of cfg.Runs strategy runs cfg.Trials times.
+// Inputs and guaranteed outputs count in full on every run. Random drops
+// fall independently with their drop chance, except items linked through
+// Pair, which form an either-or group where at most one drops. Drops are
+// scaled by the modifiers tables. Items whose table is not part of tables are
+// ignored, as in Calculate. Results are deterministic for a given seed.
+func Simulate(tables []models.StrategyTable, items []models.PricedStrategyItem, cfg SimulationConfig) models.SimulationReport {
+	rng := rand.New(rand.NewPCG(cfg.Seed, cfg.Seed))
+	items = tableItems(tables, items)
+	valuation := newValuation(tables, items)
+	scale := valuation.scale
+
+	var inputCost, outputValue, fixedValue float64
+	drops := make([]models.PricedStrategyItem, 0, len(items))
+
+	for _, item := range items {
+		value := valuation.value(item)
+
+		switch {
+		case item.Role == models.ItemRoleInput:
+			inputCost += value
+		case random(item):
+			outputValue += value
+			drops = append(drops, item)
+		default:
+			outputValue += value
+			fixedValue += value
+		}
+	}
+
+	groups := pairGroups(drops)
+	results := make([]float64, cfg.Trials)
+
+	for t := range results {
+		var total float64
+
+		for range cfg.Runs {
+			total += fixedValue - inputCost
+
+			for _, g := range groups {
+				total += g.sample(rng) * scale
+			}
+		}
+
+		results[t] = total
+	}
+
+	report := summarize(results, cfg.Bins)
+	report.Runs = cfg.Runs
+	report.Trials = cfg.Trials
+	report.Seed = cfg.Seed
+	report.ExpectedProfit = (outputValue - inputCost) * float64(cfg.Runs)
+
+	return report
+}
+
+// Samples returns how many random draws Simulate makes for cfg: one per
+// drop or either-or group, for every run of every trial.
+func Samples(tables []models.StrategyTable, items []models.PricedStrategyItem, cfg SimulationConfig) int {
+	groups := pairGroups(randomDrops(tableItems(tables, items)))
+	return cfg.Runs * cfg.Trials * len(groups)
+}
+
+func (o outcome) sample(rng *rand.Rand) float64 {
+	if !o.either {
+		item := o.items[0]
+		if rng.Float64() < float64(item.DropChance) {
+			return float64(item.Amount) * item.Price
+		}
+		return 0
+	}
+
+	// chances of an either-or group that exceed 1 are normalised so
+	// exactly one of the items drops
+	roll := rng.Float64() * math.Max(o.chance(), 1)
+
+	for _, item := range o.items {
+		roll -= float64(item.DropChance)
+		if roll < 0 {
+			return float64(item.Amount) * item.Price
+		}
+	}
+
+	return 0
+}
+
+// chance returns the combined drop chance of the items of the group.
+func (o outcome) chance() float64 {
+	var total float64
+	for _, item := range o.items {
+		total += float64(item.DropChance)
+	}
+
+	return total
+}
+
+// pairGroups groups output items connected through their Pair reference.
+func pairGroups(items []models.PricedStrategyItem) []outcome {
+	parent := make(map[int]int, len(items))
+	for _, item := range items {
+		parent[item.SID] = item.SID
+	}
+
+	var find func(int) int
+	find = func(id int) int {
+		if parent[id] != id {
+			parent[id] = find(parent[id])
+		}
+		return parent[id]
+	}
+
+	for _, item := range items {
+		if _, ok := parent[item.Pair]; ok && item.Pair != 0 {
+			parent[find(item.SID)] = find(item.Pair)
+		}
+	}
+
+	index := make(map[int]int)
+	groups := make([]outcome, 0, len(items))
+
+	for _, item := range items {
+		root := find(item.SID)
+
+		i, ok := index[root]
+		if !ok {
+			i = len(groups)
+			index[root] = i
+			groups = append(groups, outcome{})
+		}
+
+		groups[i].items = append(groups[i].items, item)
+		groups[i].either = len(groups[i].items) > 1
+	}
+
+	return groups
+}
+
+func summarize(results []float64, bins int) models.SimulationReport {
+	var report models.SimulationReport
+
+	if len(results) == 0 {
+		report.Histogram = make([]models.HistogramBin, 0)
+		return report
+	}
+
+	slices.Sort(results)
+
+	var sum, losses float64
+	for _, v := range results {
+		sum += v
+		if v < 0 {
+			losses++
+		}
+	}
+
+	n := float64(len(results))
+	report.Mean = sum / n
+	report.Min = results[0]
+	report.Max = results[len(results)-1]
+	report.ProbabilityOfLoss = losses / n
+
+	var variance float64
+	for _, v := range results {
+		variance += (v - report.Mean) * (v - report.Mean)
+	}
+	report.StdDev = math.Sqrt(variance / n)
+
+	report.Percentiles = models.Percentiles{
+		P5:  percentile(results, 0.05),
+		P25: percentile(results, 0.25),
+		P50: percentile(results, 0.50),
+		P75: percentile(results, 0.75),
+		P95: percentile(results, 0.95),
+	}
+
+	report.Histogram = histogram(results, bins)
+
+	return report
+}
+
+// percentile expects sorted values and interpolates between closest ranks.
+func percentile(sorted []float64, p float64) float64 {
+	rank := p * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(rank-float64(lo))
+}
+
+func histogram(sorted []float64, bins int) []models.HistogramBin {
+	lo, hi := sorted[0], sorted[len(sorted)-1]
+
+	if hi == lo {
+		return []models.HistogramBin{{From: lo, To: hi, Count: len(sorted)}}
+	}
+
+	width := (hi - lo) / float64(bins)
+	result := make([]models.HistogramBin, bins)
+
+	for i := range result {
+		result[i].From = lo + float64(i)*width
+		result[i].To = lo + float64(i+1)*width
+	}
+
+	for _, v := range sorted {
+		i := min(int((v-lo)/width), bins-1)
+		result[i].Count++
+	}
+
+	return result
+}
@@ -0,0 +1,238 @@
+package profit
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+var (
+	goldenTables = []models.StrategyTable{
+		{ID: 1, Type: models.TableTypeInputs},
+		{ID: 3, Type: models.TableTypeDrops},
+		{ID: 4, Type: models.TableTypeEitherOr},
+		{ID: 5, Type: models.TableTypeModifiers, Scale: 1.5},
+	}
+	goldenItems = []models.PricedStrategyItem{
+		item(1, 1, models.TableTypeInputs, 1, 1, 20),
+		item(2, 3, models.TableTypeDrops, 1, 0.25, 40),
+		item(3, 3, models.TableTypeDrops, 2, 0.5, 5),
+		item(4, 4, models.TableTypeEitherOr, 1, 0.125, 100),
+		paired(item(5, 4, models.TableTypeEitherOr, 1, 0.25, 30), 4),
+		item(6, 5, models.TableTypeModifiers, 1, 1, 2),
+	}
+)
+
+// TestSimulateGolden pins the outcome of a seeded simulation. A change in
+// these numbers means the sampling changed and old seeds no longer
+// reproduce their results.
+func TestSimulateGolden(t *testing.T) {
+	report := Simulate(goldenTables, goldenItems, SimulationConfig{Runs: 10, Trials: 1000, Bins: 5, Seed: 42})
+
+	want := models.SimulationReport{
+		Runs:              10,
+		Trials:            1000,
+		Seed:              42,
+		ExpectedProfit:    305,
+		Mean:              310.67,
+		StdDev:            176.22102910833308,
+		Min:               -70,
+		Max:               980,
+		ProbabilityOfLoss: 0.019,
+		Percentiles:       models.Percentiles{P5: 35, P25: 185, P50: 305, P75: 425, P95: 620},
+		Histogram: []models.HistogramBin{
+			{From: -70, To: 140, Count: 155},
+			{From: 140, To: 350, Count: 442},
+			{From: 350, To: 560, Count: 305},
+			{From: 560, To: 770, Count: 86},
+			{From: 770, To: 980, Count: 12},
+		},
+	}
+
+	if !approxEqual(report.StdDev, want.StdDev) {
+		t.Errorf("StdDev = %v, want %v", report.StdDev, want.StdDev)
+	}
+	report.StdDev = want.StdDev
+
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("Simulate() =\n%+v\nwant\n%+v", report, want)
+	}
+}
+
+func TestSimulateDeterministic(t *testing.T) {
+	cfg := SimulationConfig{Runs: 5, Trials: 200, Bins: 10, Seed: 7}
+
+	first := Simulate(goldenTables, goldenItems, cfg)
+	second := Simulate(goldenTables, goldenItems, cfg)
+	if !reflect.DeepEqual(first, second) {
+		t.Error("Simulate() differs between runs with the same seed")
+	}
+
+	cfg.Seed = 8
+	if other := Simulate(goldenTables, goldenItems, cfg); reflect.DeepEqual(first.Histogram, other.Histogram) {
+		t.Error("Simulate() is identical for different seeds")
+	}
+}
+
+func TestSimulateIgnoresUnknownTables(t *testing.T) {
+	items := append(slices.Clone(goldenItems), item(7, 99, models.TableTypeDrops, 1, 1, 1000))
+	cfg := SimulationConfig{Runs: 10, Trials: 100, Bins: 5, Seed: 42}
+
+	if got, want := Simulate(goldenTables, items, cfg), Simulate(goldenTables, goldenItems, cfg); !reflect.DeepEqual(got, want) {
+		t.Errorf("Simulate() with an unknown table = %+v, want %+v", got, want)
+	}
+
+	report := Calculate(goldenTables, items)
+	if sim := Simulate(goldenTables, items, SimulationConfig{Runs: 1, Trials: 1, Bins: 1}); !approxEqual(sim.ExpectedProfit, report.NetProfit) {
+		t.Errorf("Simulate() expects %v, Calculate() = %v", sim.ExpectedProfit, report.NetProfit)
+	}
+}
+
+func TestSamples(t *testing.T) {
+	items := append(slices.Clone(goldenItems), item(7, 99, models.TableTypeDrops, 1, 1, 1000))
+
+	// two drops and one either-or group, the unknown table is left out
+	if got := Samples(goldenTables, items, SimulationConfig{Runs: 10, Trials: 100}); got != 3000 {
+		t.Errorf("Samples() = %d, want 3000", got)
+	}
+}
+
+func TestSimulateEitherOr(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []models.PricedStrategyItem
+		// values lists every value a single run of the group may produce
+		values []float64
+	}{
+		{
+			name: "at most one item of a group drops",
+			items: []models.PricedStrategyItem{
+				item(1, 4, models.TableTypeEitherOr, 1, 0.5, 100),
+				paired(item(2, 4, models.TableTypeEitherOr, 1, 0.5, 10), 1),
+			},
+			values: []float64{100, 10},
+		},
+		{
+			name: "group below one may drop nothing",
+			items: []models.PricedStrategyItem{
+				item(1, 4, models.TableTypeEitherOr, 1, 0.25, 100),
+				paired(item(2, 4, models.TableTypeEitherOr, 1, 0.25, 10), 1),
+			},
+			values: []float64{100, 10, 0},
+		},
+		{
+			name: "group above one always drops one",
+			items: []models.PricedStrategyItem{
+				item(1, 4, models.TableTypeEitherOr, 1, 1, 100),
+				paired(item(2, 4, models.TableTypeEitherOr, 1, 1, 10), 1),
+			},
+			values: []float64{100, 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := pairGroups(tt.items)
+			if len(groups) != 1 {
+				t.Fatalf("pairGroups() returned %d groups, want 1", len(groups))
+			}
+
+			rng := rand.New(rand.NewPCG(3, 3))
+			seen := make(map[float64]bool)
+			for range 1000 {
+				v := groups[0].sample(rng)
+				if !slices.Contains(tt.values, v) {
+					t.Fatalf("sample() = %v, want one of %v", v, tt.values)
+				}
+				seen[v] = true
+			}
+
+			if len(seen) != len(tt.values) {
+				t.Errorf("sample() produced %v, want every one of %v", seen, tt.values)
+			}
+		})
+	}
+}
+
+func TestPairGroups(t *testing.T) {
+	items := []models.PricedStrategyItem{
+		item(1, 4, models.TableTypeEitherOr, 1, 0.25, 1),
+		paired(item(2, 4, models.TableTypeEitherOr, 1, 0.25, 1), 1),
+		paired(item(3, 4, models.TableTypeEitherOr, 1, 0.25, 1), 2),
+		item(4, 3, models.TableTypeDrops, 1, 0.25, 1),
+		// a pair outside of the items does not join a group
+		paired(item(5, 3, models.TableTypeDrops, 1, 0.25, 1), 99),
+	}
+
+	groups := pairGroups(items)
+
+	sizes := make([]int, len(groups))
+	for i, g := range groups {
+		sizes[i] = len(g.items)
+		if g.either != (len(g.items) > 1) {
+			t.Errorf("group %d: either = %v with %d items", i, g.either, len(g.items))
+		}
+	}
+
+	if want := []int{3, 1, 1}; !reflect.DeepEqual(sizes, want) {
+		t.Errorf("group sizes = %v, want %v", sizes, want)
+	}
+
+	if got := groups[0].chance(); !approxEqual(got, 0.75) {
+		t.Errorf("chance() = %v, want 0.75", got)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		report := summarize(nil, 5)
+		if report.Histogram == nil || len(report.Histogram) != 0 {
+			t.Errorf("Histogram = %v, want empty", report.Histogram)
+		}
+	})
+
+	t.Run("constant results", func(t *testing.T) {
+		report := summarize([]float64{4, 4, 4}, 5)
+		want := []models.HistogramBin{{From: 4, To: 4, Count: 3}}
+		if !reflect.DeepEqual(report.Histogram, want) {
+			t.Errorf("Histogram = %v, want %v", report.Histogram, want)
+		}
+		if report.StdDev != 0 {
+			t.Errorf("StdDev = %v, want 0", report.StdDev)
+		}
+	})
+
+	t.Run("statistics", func(t *testing.T) {
+		report := summarize([]float64{5, -1, 3, 1, -3}, 2)
+
+		checks := []struct {
+			name      string
+			got, want float64
+		}{
+			{"Mean", report.Mean, 1},
+			{"Min", report.Min, -3},
+			{"Max", report.Max, 5},
+			{"StdDev", report.StdDev, 2.8284271247461903},
+			{"ProbabilityOfLoss", report.ProbabilityOfLoss, 0.4},
+			{"P5", report.Percentiles.P5, -2.6},
+			{"P25", report.Percentiles.P25, -1},
+			{"P50", report.Percentiles.P50, 1},
+			{"P75", report.Percentiles.P75, 3},
+			{"P95", report.Percentiles.P95, 4.6},
+		}
+
+		for _, c := range checks {
+			if !approxEqual(c.got, c.want) {
+				t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+			}
+		}
+
+		want := []models.HistogramBin{{From: -3, To: 1, Count: 2}, {From: 1, To: 5, Count: 3}}
+		if !reflect.DeepEqual(report.Histogram, want) {
+			t.Errorf("Histogram = %v, want %v", report.Histogram, want)
+		}
+	})
+}
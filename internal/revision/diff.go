@@ -0,0 +1,126 @@
+// Package revision compares strategy snapshots.
+package revision
+
+import (
+	"strings"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+// Diff lists the changes needed to go from snapshot a to snapshot b.
+// Tables and items are matched by their ids.
+func Diff(a, b models.StrategySnapshot) models.RevisionDiff {
+	diff := models.RevisionDiff{
+		Fields: make([]models.FieldChange, 0),
+		Tables: make([]models.TableChange, 0),
+	}
+
+	diff.Fields = compare(diff.Fields, "name", a.Name, b.Name)
+	diff.Fields = compare(diff.Fields, "description", a.Description, b.Description)
+	diff.Fields = compare(diff.Fields, "atlas", a.Atlas, b.Atlas)
+	diff.Fields = compare(diff.Fields, "atlas_tree.code", atlasCode(a.AtlasTree), atlasCode(b.AtlasTree))
+	diff.Fields = compare(diff.Fields, "atlas_tree.mechanics", atlasMechanics(a.AtlasTree), atlasMechanics(b.AtlasTree))
+	diff.Fields = compare(diff.Fields, "duration", a.Duration, b.Duration)
+	diff.Fields = compare(diff.Fields, "league", a.League, b.League)
+
+	before := make(map[int]models.SnapshotTable, len(a.Tables))
+	for _, t := range a.Tables {
+		before[t.ID] = t
+	}
+
+	for _, t := range b.Tables {
+		old, ok := before[t.ID]
+		if !ok {
+			diff.Tables = append(diff.Tables, models.TableChange{TableID: t.ID, Title: t.Title, Change: models.ChangeAdded})
+			continue
+		}
+		delete(before, t.ID)
+
+		change := models.TableChange{TableID: t.ID, Title: t.Title, Change: models.ChangeModified}
+		change.Fields = compare(change.Fields, "type", old.Type, t.Type)
+		change.Fields = compare(change.Fields, "title", old.Title, t.Title)
+		change.Fields = compare(change.Fields, "scale", old.Scale, t.Scale)
+		change.Items = diffItems(old.Items, t.Items)
+
+		if len(change.Fields) > 0 || len(change.Items) > 0 {
+			diff.Tables = append(diff.Tables, change)
+		}
+	}
+
+	for _, t := range a.Tables {
+		if _, ok := before[t.ID]; ok {
+			diff.Tables = append(diff.Tables, models.TableChange{TableID: t.ID, Title: t.Title, Change: models.ChangeRemoved})
+		}
+	}
+
+	return diff
+}
+
+func atlasCode(tree *models.AtlasTree) string {
+	if tree == nil {
+		return ""
+	}
+	return tree.Code
+}
+
+func atlasMechanics(tree *models.AtlasTree) string {
+	if tree == nil {
+		return ""
+	}
+	return strings.Join(tree.Mechanics, ", ")
+}
+
+func diffItems(a, b []models.StrategyItem) []models.ItemChange {
+	var changes []models.ItemChange
+
+	before := make(map[int]models.StrategyItem, len(a))
+	for _, item := range a {
+		before[item.SID] = item
+	}
+
+	for _, item := range b {
+		old, ok := before[item.SID]
+		if !ok {
+			changes = append(changes, models.ItemChange{ID: item.SID, ItemID: item.ItemID, Change: models.ChangeAdded})
+			continue
+		}
+		delete(before, item.SID)
+
+		var fields []models.FieldChange
+		fields = compare(fields, "item_id", old.ItemID, item.ItemID)
+		fields = compare(fields, "amount", old.Amount, item.Amount)
+		fields = compare(fields, "role", old.Role, item.Role)
+		fields = compare(fields, "drop_chance", old.DropChance, item.DropChance)
+		fields = compare(fields, "pair", old.Pair, item.Pair)
+		fields = compareOptional(fields, "price_override", old.PriceOverride, item.PriceOverride)
+
+		if len(fields) > 0 {
+			changes = append(changes, models.ItemChange{ID: item.SID, ItemID: item.ItemID, Change: models.ChangeModified, Fields: fields})
+		}
+	}
+
+	for _, item := range a {
+		if _, ok := before[item.SID]; ok {
+			changes = append(changes, models.ItemChange{ID: item.SID, ItemID: item.ItemID, Change: models.ChangeRemoved})
+		}
+	}
+
+	return changes
+}
+
+func compare[T comparable](changes []models.FieldChange, field string, from, to T) []models.FieldChange {
+	if from == to {
+		return changes
+	}
+
+	return append(changes, models.FieldChange{Field: field, From: from, To: to})
+}
+
+// compareOptional compares optional values, nil meaning unset.
+func compareOptional[T comparable](changes []models.FieldChange, field string, from, to *T) []models.FieldChange {
+	if from == nil && to == nil || from != nil && to != nil && *from == *to {
+		return changes
+	}
+
+	return append(changes, models.FieldChange{Field: field, From: from, To: to})
+}
@@ -0,0 +1,173 @@
+package revision
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+func table(id int, title string, items ...models.StrategyItem) models.SnapshotTable {
+	return models.SnapshotTable{
+		StrategyTable: models.StrategyTable{ID: id, Title: title},
+		Items:         items,
+	}
+}
+
+func item(sid int, itemID string, amount int) models.StrategyItem {
+	return models.StrategyItem{SID: sid, ItemID: itemID, Amount: amount, Role: models.ItemRoleOutput, DropChance: 1}
+}
+
+func TestDiff(t *testing.T) {
+	price := 5.0
+
+	base := models.StrategySnapshot{
+		Name:     "Harvest",
+		Atlas:    "none",
+		Duration: 60,
+		Tables: []models.SnapshotTable{
+			table(1, "Drops", item(10, "orb", 1), item(11, "scarab", 2)),
+		},
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(s *models.StrategySnapshot)
+		fields []models.FieldChange
+		tables []models.TableChange
+	}{
+		{
+			name:   "identical",
+			mutate: func(s *models.StrategySnapshot) {},
+			fields: []models.FieldChange{},
+			tables: []models.TableChange{},
+		},
+		{
+			name: "field changed",
+			mutate: func(s *models.StrategySnapshot) {
+				s.Name = "Harvest farming"
+				s.Atlas = "harvest"
+				s.Duration = 90
+				s.League = "chc"
+			},
+			fields: []models.FieldChange{
+				{Field: "name", From: "Harvest", To: "Harvest farming"},
+				{Field: "atlas", From: "none", To: "harvest"},
+				{Field: "duration", From: int64(60), To: int64(90)},
+				{Field: "league", From: "", To: "chc"},
+			},
+			tables: []models.TableChange{},
+		},
+		{
+			name: "atlas tree added",
+			mutate: func(s *models.StrategySnapshot) {
+				s.AtlasTree = &models.AtlasTree{Code: "AAAA", Mechanics: []string{"harvest", "ritual"}}
+			},
+			fields: []models.FieldChange{
+				{Field: "atlas_tree.code", From: "", To: "AAAA"},
+				{Field: "atlas_tree.mechanics", From: "", To: "harvest, ritual"},
+			},
+			tables: []models.TableChange{},
+		},
+		{
+			name: "table added",
+			mutate: func(s *models.StrategySnapshot) {
+				s.Tables = append(s.Tables, table(2, "Costs"))
+			},
+			fields: []models.FieldChange{},
+			tables: []models.TableChange{
+				{TableID: 2, Title: "Costs", Change: models.ChangeAdded},
+			},
+		},
+		{
+			name: "table removed",
+			mutate: func(s *models.StrategySnapshot) {
+				s.Tables = nil
+			},
+			fields: []models.FieldChange{},
+			tables: []models.TableChange{
+				{TableID: 1, Title: "Drops", Change: models.ChangeRemoved},
+			},
+		},
+		{
+			name: "table renamed and rescaled",
+			mutate: func(s *models.StrategySnapshot) {
+				renamed := table(1, "Loot", item(10, "orb", 1), item(11, "scarab", 2))
+				renamed.Scale = 2
+				s.Tables = []models.SnapshotTable{renamed}
+			},
+			fields: []models.FieldChange{},
+			tables: []models.TableChange{
+				{
+					TableID: 1,
+					Title:   "Loot",
+					Change:  models.ChangeModified,
+					Fields: []models.FieldChange{
+						{Field: "title", From: "Drops", To: "Loot"},
+						{Field: "scale", From: 0.0, To: 2.0},
+					},
+				},
+			},
+		},
+		{
+			name: "items added, removed and changed",
+			mutate: func(s *models.StrategySnapshot) {
+				changed := item(10, "orb", 3)
+				changed.DropChance = 0.5
+				changed.PriceOverride = &price
+				s.Tables = []models.SnapshotTable{table(1, "Drops", changed, item(12, "fossil", 1))}
+			},
+			fields: []models.FieldChange{},
+			tables: []models.TableChange{
+				{
+					TableID: 1,
+					Title:   "Drops",
+					Change:  models.ChangeModified,
+					Items: []models.ItemChange{
+						{
+							ID:     10,
+							ItemID: "orb",
+							Change: models.ChangeModified,
+							Fields: []models.FieldChange{
+								{Field: "amount", From: 1, To: 3},
+								{Field: "drop_chance", From: float32(1), To: float32(0.5)},
+								{Field: "price_override", From: (*float64)(nil), To: &price},
+							},
+						},
+						{ID: 12, ItemID: "fossil", Change: models.ChangeAdded},
+						{ID: 11, ItemID: "scarab", Change: models.ChangeRemoved},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := base
+			next.Tables = append([]models.SnapshotTable(nil), base.Tables...)
+			tt.mutate(&next)
+
+			diff := Diff(base, next)
+			if !reflect.DeepEqual(diff.Fields, tt.fields) {
+				t.Errorf("Fields = %+v, want %+v", diff.Fields, tt.fields)
+			}
+			if !reflect.DeepEqual(diff.Tables, tt.tables) {
+				t.Errorf("Tables = %+v, want %+v", diff.Tables, tt.tables)
+			}
+		})
+	}
+}
+
+func TestDiffOptionalUnchanged(t *testing.T) {
+	a, b := 5.0, 5.0
+
+	before := item(1, "orb", 1)
+	before.PriceOverride = &a
+	after := item(1, "orb", 1)
+	after.PriceOverride = &b
+
+	if changes := diffItems([]models.StrategyItem{before}, []models.StrategyItem{after}); len(changes) != 0 {
+		t.Errorf("diffItems() = %+v, want no changes for equal overrides", changes)
+	}
+}
@@ -0,0 +1,220 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+// access is the level of permission a user has on a strategy.
+type access int
+
+const (
+	accessNone access = iota
+	accessViewer
+	accessEditor
+	accessOwner
+)
+
+// accessLevel resolves the user's permission on a strategy from ownership
+// and accepted collaborator invitations.
+func (s *Server) accessLevel(ctx context.Context, strategy *models.Strategy, user *models.UserProfile) (access, error) {
+	if user == nil {
+		return accessNone, nil
+	}
+
+	if strategy.UserID == user.ID {
+		return accessOwner, nil
+	}
+
+	role, err := s.db.RetrieveCollaboratorRole(ctx, strategy.ID, user.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return accessNone, nil
+	}
+	if err != nil {
+		return accessNone, err
+	}
+
+	switch role {
+	case models.CollaboratorEditor:
+		return accessEditor, nil
+	case models.CollaboratorViewer:
+		return accessViewer, nil
+	}
+
+	return accessNone, nil
+}
+
+// loadStrategy resolves the {strategy_id} path value into a strategy.
+// On failure the error response has already been written.
+func (s *Server) loadStrategy(w http.ResponseWriter, r *http.Request) (*models.Strategy, bool) {
+	strategyID, err := PathID(r, "strategy_id")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return nil, false
+	}
+
+	strategy, err := s.db.RetrieveStrategy(r.Context(), strategyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			NewError(r.Context(), w, http.StatusNotFound, "No strategy found with this ID", r.URL.Path)
+			return nil, false
+		}
+
+		NewInternalError(r.Context(), w, "retrieving strategy", err, r.URL.Path)
+		return nil, false
+	}
+
+	return strategy, true
+}
+
+// viewableStrategy loads the strategy from the path and checks that the
+// caller may read it: public strategies are readable by anyone, private ones
+// by their owner, collaborators or holders of a valid share token.
+func (s *Server) viewableStrategy(w http.ResponseWriter, r *http.Request) (*models.Strategy, bool) {
+	strategy, ok := s.loadStrategy(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	if strategy.Public {
+		return strategy, true
+	}
+
+	user, _ := GetUser(r)
+
+	level, err := s.accessLevel(r.Context(), strategy, user)
+	if err != nil {
+		NewInternalError(r.Context(), w, "resolving strategy access", err, r.URL.Path)
+		return nil, false
+	}
+
+	if level >= accessViewer {
+		return strategy, true
+	}
+
+	if token := shareToken(r); token != "" {
+		valid, err := s.db.IsShareTokenValid(r.Context(), strategy.ID, token)
+		if err != nil {
+			NewInternalError(r.Context(), w, "validating share token", err, r.URL.Path)
+			return nil, false
+		}
+
+		if valid {
+			return strategy, true
+		}
+	}
+
+	if user == nil {
+		NewError(r.Context(), w, http.StatusUnauthorized, "Strategy is private.", r.URL.Path)
+		return nil, false
+	}
+
+	NewError(r.Context(), w, http.StatusForbidden, "Strategy is private.", r.URL.Path)
+	return nil, false
+}
+
+// editableStrategy loads the strategy from the path and checks that the
+// authenticated user owns it or collaborates on it as an editor.
+func (s *Server) editableStrategy(w http.ResponseWriter, r *http.Request) (*models.Strategy, bool) {
+	return s.authorizedStrategy(w, r, accessEditor)
+}
+
+// ownedStrategy loads the strategy from the path and checks that the
+// authenticated user owns it.
+func (s *Server) ownedStrategy(w http.ResponseWriter, r *http.Request) (*models.Strategy, bool) {
+	return s.authorizedStrategy(w, r, accessOwner)
+}
+
+func (s *Server) authorizedStrategy(w http.ResponseWriter, r *http.Request, required access) (*models.Strategy, bool) {
+	user, err := GetUser(r)
+	if err != nil {
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
+		return nil, false
+	}
+
+	strategy, ok := s.loadStrategy(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	level, err := s.accessLevel(r.Context(), strategy, user)
+	if err != nil {
+		NewInternalError(r.Context(), w, "resolving strategy access", err, r.URL.Path)
+		return nil, false
+	}
+
+	if level < required {
+		NewError(r.Context(), w, http.StatusForbidden, "You do not have permission to modify this strategy.", r.URL.Path)
+		return nil, false
+	}
+
+	return strategy, true
+}
+
+// forkableStrategy loads the strategy from the path and checks that the
+// authenticated user may copy it: public strategies can be forked by anyone,
+// private ones only by their owner and editors. Viewer collaborators and
+// share links grant read access, not a copy.
+func (s *Server) forkableStrategy(w http.ResponseWriter, r *http.Request) (*models.Strategy, *models.UserProfile, bool) {
+	user, err := GetUser(r)
+	if err != nil {
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
+		return nil, nil, false
+	}
+
+	strategy, ok := s.loadStrategy(w, r)
+	if !ok {
+		return nil, nil, false
+	}
+
+	if strategy.Public {
+		return strategy, user, true
+	}
+
+	level, err := s.accessLevel(r.Context(), strategy, user)
+	if err != nil {
+		NewInternalError(r.Context(), w, "resolving strategy access", err, r.URL.Path)
+		return nil, nil, false
+	}
+
+	if level < accessEditor {
+		NewError(r.Context(), w, http.StatusForbidden, "Only public strategies can be forked.", r.URL.Path)
+		return nil, nil, false
+	}
+
+	return strategy, user, true
+}
+
+// historyStrategy loads the strategy from the path and checks that the
+// authenticated user may read its revisions. Revisions keep the content
+// from before a strategy was made public, so only the owner and editors
+// can read them.
+func (s *Server) historyStrategy(w http.ResponseWriter, r *http.Request) (*models.Strategy, bool) {
+	user, err := GetUser(r)
+	if err != nil {
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
+		return nil, false
+	}
+
+	strategy, ok := s.loadStrategy(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	level, err := s.accessLevel(r.Context(), strategy, user)
+	if err != nil {
+		NewInternalError(r.Context(), w, "resolving strategy access", err, r.URL.Path)
+		return nil, false
+	}
+
+	if level < accessEditor {
+		NewError(r.Context(), w, http.StatusForbidden, "Only editors can read the revision history.", r.URL.Path)
+		return nil, false
+	}
+
+	return strategy, true
+}
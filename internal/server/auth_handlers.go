@@ -1,14 +1,18 @@
 package server
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/Vyary/api/internal/database"
 	"github.com/Vyary/api/internal/models"
 	_ "github.com/joho/godotenv/autoload"
 )
@@ -160,7 +164,7 @@ func (s *Server) ExchangeHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tokenPair, err := s.GenTokenPair(user)
+	tokenPair, err := s.GenTokenPair(user, "", sessionClient(r))
 	if err != nil {
 		slog.Error("failed to gen JWT tokens", "error", err, "user_uuid", user.ID)
 		writeError(w, http.StatusInternalServerError, "Failed to create authentication token")
@@ -185,23 +189,43 @@ func (s *Server) ExchangeHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// TokenRefreshHandler exchanges a refresh token for a new token pair of the
+// same family. Presenting a token that was already exchanged means it leaked,
+// so the whole family is revoked and the event logged.
 func (s *Server) TokenRefreshHandler(w http.ResponseWriter, r *http.Request) {
-	claims, err := GetClaims(r)
+	claims, err := GetRefreshClaims(r)
 	if err != nil {
 		writeError(w, http.StatusUnauthorized, err.Error())
 		return
 	}
 
-	if !s.db.IsRefreshTokenValid(claims.TokenID) {
-		writeError(w, http.StatusUnauthorized, "Refresh token revoked")
+	if err := s.db.RotateRefreshToken(r.Context(), claims.TokenID); err != nil {
+		switch {
+		case errors.Is(err, database.ErrRefreshTokenReused):
+			slog.Warn("security event: refresh token reuse detected, revoking token family",
+				"user_id", claims.UserID, "family_id", claims.FamilyID, "token_id", claims.TokenID,
+				"remote_addr", r.RemoteAddr, "user_agent", r.UserAgent())
+
+			if err := s.db.RevokeRefreshTokenFamily(r.Context(), claims.UserID, claims.FamilyID); err != nil {
+				slog.Error("failed to revoke token family", "error", err, "user_id", claims.UserID, "family_id", claims.FamilyID)
+			}
+			// access tokens issued to the family are still in circulation
+			if err := s.revokeAccess(r.Context(), claims.FamilyID, time.Now().Add(jwtExpiration)); err != nil {
+				slog.Error("failed to revoke access tokens", "error", err, "user_id", claims.UserID, "family_id", claims.FamilyID)
+			}
+
+			clearJWTCookies(w)
+			writeError(w, http.StatusUnauthorized, "Refresh token reused")
+		case errors.Is(err, sql.ErrNoRows):
+			writeError(w, http.StatusUnauthorized, "Refresh token revoked")
+		default:
+			slog.Error("failed to rotate refresh token", "error", err, "user_id", claims.UserID, "token_id", claims.TokenID)
+			writeError(w, http.StatusInternalServerError, "Failed to refresh authentication token")
+		}
 		return
 	}
 
-	if err = s.db.RevokeRefreshToken(claims.UserID, claims.TokenID); err != nil {
-		slog.Error("failed to revoke token", "user_id", claims.UserID, "token_id", claims.TokenID)
-	}
-
-	tokenPair, err := s.GenTokenPair(models.UserProfile{ID: claims.UserID, Name: claims.UserName})
+	tokenPair, err := s.GenTokenPair(models.UserProfile{ID: claims.UserID, Name: claims.UserName}, claims.FamilyID, sessionClient(r))
 	if err != nil {
 		slog.Error("failed to gen JWT tokens", "error", err, "user_uuid", claims.UserID)
 		writeError(w, http.StatusInternalServerError, "Failed to create authentication token")
@@ -214,14 +238,17 @@ func (s *Server) TokenRefreshHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) LogoutHandler(w http.ResponseWriter, r *http.Request) {
-	claims, err := GetClaims(r)
+	claims, err := logoutClaims(r)
 	if err != nil {
 		writeError(w, http.StatusUnauthorized, err.Error())
 		return
 	}
 
-	if err := s.db.RevokeRefreshToken(claims.UserID, claims.TokenID); err != nil {
-		slog.Error("failed to revoke refresh token", "user_id", claims.UserID, "token_id", claims.TokenID)
+	if err := s.db.RevokeRefreshTokenFamily(r.Context(), claims.UserID, claims.FamilyID); err != nil {
+		slog.Error("failed to revoke refresh token", "user_id", claims.UserID, "family_id", claims.FamilyID)
+	}
+	if err := s.revokeSession(r.Context(), claims); err != nil {
+		slog.Error("failed to revoke access tokens", "error", err, "user_id", claims.UserID, "family_id", claims.FamilyID)
 	}
 
 	clearJWTCookies(w)
@@ -230,7 +257,7 @@ func (s *Server) LogoutHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) LogoutAllHandler(w http.ResponseWriter, r *http.Request) {
-	claims, err := GetClaims(r)
+	claims, err := logoutClaims(r)
 	if err != nil {
 		writeError(w, http.StatusUnauthorized, err.Error())
 		return
@@ -239,6 +266,19 @@ func (s *Server) LogoutAllHandler(w http.ResponseWriter, r *http.Request) {
 	if err := s.db.RemoveOAuthToken(claims.UserID); err != nil {
 		slog.Error("failed to remove OAuth token", "user_id", claims.UserID)
 	}
+	sessions, err := s.db.ListSessions(r.Context(), claims.UserID)
+	if err != nil {
+		slog.Error("failed to list sessions", "error", err, "user_id", claims.UserID)
+	}
+	if err := s.revokeSession(r.Context(), claims); err != nil {
+		slog.Error("failed to revoke access tokens", "error", err, "user_id", claims.UserID, "family_id", claims.FamilyID)
+	}
+	for _, session := range sessions {
+		if err := s.revokeAccess(r.Context(), session.ID, time.Now().Add(jwtExpiration)); err != nil {
+			slog.Error("failed to revoke access tokens", "error", err, "user_id", claims.UserID, "family_id", session.ID)
+		}
+	}
+
 	if err := s.db.RevokeAllRefreshTokens(claims.UserID); err != nil {
 		slog.Error("failed to revoke refresh token", "user_id", claims.UserID, "token_id", claims.TokenID)
 	}
@@ -247,3 +287,18 @@ func (s *Server) LogoutAllHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// logoutClaims identifies the session to log out. Access tokens are short
+// lived, so a client that has been idle may only hold a valid refresh token.
+func logoutClaims(r *http.Request) (*models.JWTClaims, error) {
+	claims, err := GetClaims(r)
+	if err == nil {
+		return claims, nil
+	}
+
+	if refreshClaims, refreshErr := GetRefreshClaims(r); refreshErr == nil {
+		return refreshClaims, nil
+	}
+
+	return nil, err
+}
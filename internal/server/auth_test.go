@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Vyary/api/internal/database"
+	"github.com/Vyary/api/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signedToken signs a token issued at the given time with the given
+// audience, if any.
+func signedToken(t *testing.T, issued time.Time, audience ...string) string {
+	t.Helper()
+
+	claims := models.JWTClaims{
+		UserID:   "user",
+		UserName: "Exile",
+		TokenID:  "token",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(issued.Add(jwtRefreshExpiration)),
+			IssuedAt:  jwt.NewNumericDate(issued),
+			Issuer:    "exile-profit",
+			Subject:   "user",
+		},
+	}
+	if len(audience) > 0 {
+		claims.Audience = audience
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(jwtSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func TestGetRefreshClaims(t *testing.T) {
+	now := time.Now()
+
+	introduced := audiencesIntroduced
+	audiencesIntroduced = now.Add(-time.Hour)
+	t.Cleanup(func() { audiencesIntroduced = introduced })
+
+	tests := []struct {
+		name  string
+		token string
+		ok    bool
+	}{
+		{"refresh token", signedToken(t, now, refreshAudience), true},
+		{"access token", signedToken(t, now, accessAudience), false},
+		{"legacy token without audience", signedToken(t, now.Add(-2*time.Hour)), true},
+		{"new token without audience", signedToken(t, now), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/auth/poe/refresh", nil)
+			r.AddCookie(&http.Cookie{Name: "jwt_refresh", Value: tt.token})
+
+			claims, err := GetRefreshClaims(r)
+			if ok := err == nil; ok != tt.ok {
+				t.Fatalf("GetRefreshClaims() error = %v, want ok %v", err, tt.ok)
+			}
+			if tt.ok && claims.UserID != "user" {
+				t.Errorf("UserID = %q, want %q", claims.UserID, "user")
+			}
+		})
+	}
+}
+
+// sessionDB records the revocations of a logout.
+type sessionDB struct {
+	database.Service
+	revokedFamilies []string
+	revokedAccess   []string
+}
+
+func (db *sessionDB) StoreRefreshToken(userID string, tokenID string, familyID string, expiration time.Duration, client models.SessionClient) error {
+	return nil
+}
+
+func (db *sessionDB) RevokeRefreshTokenFamily(ctx context.Context, userID string, familyID string) error {
+	db.revokedFamilies = append(db.revokedFamilies, familyID)
+	return nil
+}
+
+func (db *sessionDB) RevokeAccessToken(ctx context.Context, id string, expiresAt int64) error {
+	db.revokedAccess = append(db.revokedAccess, id)
+	return nil
+}
+
+func TestLogoutWithRefreshCookieOnly(t *testing.T) {
+	db := &sessionDB{}
+	s := &Server{db: db}
+
+	pair, err := s.GenTokenPair(models.UserProfile{ID: "user", Name: "Exile"}, "family", models.SessionClient{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	login := httptest.NewRecorder()
+	setJWTCookies(login, *pair)
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, _ := url.Parse("https://api.example.com/auth/poe/exchange")
+	jar.SetCookies(base, login.Result().Cookies())
+
+	// the access token has expired, only the refresh token is left
+	logoutURL, _ := url.Parse("https://api.example.com/auth/poe/logout")
+	r := httptest.NewRequest(http.MethodPost, logoutURL.String(), nil)
+	for _, c := range jar.Cookies(logoutURL) {
+		if c.Name == "jwt_refresh" {
+			r.AddCookie(c)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	s.LogoutHandler(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	if !slices.Equal(db.revokedFamilies, []string{"family"}) {
+		t.Errorf("revoked refresh families = %v, want [family]", db.revokedFamilies)
+	}
+	if !slices.Equal(db.revokedAccess, []string{"family"}) {
+		t.Errorf("revoked access tokens = %v, want [family]", db.revokedAccess)
+	}
+
+	jar.SetCookies(logoutURL, w.Result().Cookies())
+	if cookies := jar.Cookies(logoutURL); len(cookies) != 0 {
+		t.Errorf("cookies left after logout = %v, want none", cookies)
+	}
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Vyary/api/internal/database"
+	"github.com/Vyary/api/internal/models"
+)
+
+func (s *Server) ListCollaboratorsHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.ownedStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	collaborators, err := s.db.ListCollaborators(r.Context(), strategy.ID)
+	if err != nil {
+		NewInternalError(r.Context(), w, "listing collaborators", err, r.URL.Path)
+		return
+	}
+
+	WriteJSON(r.Context(), w, http.StatusOK, collaborators)
+}
+
+func (s *Server) InviteCollaboratorHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.ownedStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := GetUser(r)
+	if err != nil {
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
+		return
+	}
+
+	var req models.CollaboratorRequest
+	statusCode, err := DecodeJSON(r, &req)
+	if err != nil {
+		NewError(r.Context(), w, statusCode, err.Error(), r.URL.Path)
+		return
+	}
+
+	req.Username = strings.TrimSpace(req.Username)
+	if req.Role == "" {
+		req.Role = models.CollaboratorViewer
+	}
+
+	errs := Errors{}
+	switch {
+	case req.Username == "":
+		errs["username"] = "must not be empty"
+	case req.Username == user.Name:
+		errs["username"] = "cannot invite yourself"
+	}
+	if req.Role != models.CollaboratorViewer && req.Role != models.CollaboratorEditor {
+		errs["role"] = "must be viewer or editor"
+	}
+
+	if len(errs) > 0 {
+		NewFieldErrors(r.Context(), w, http.StatusUnprocessableEntity, "Invalid invitation.", errs, r.URL.Path)
+		return
+	}
+
+	collaborator, err := s.db.InviteCollaborator(r.Context(), strategy.ID, req.Username, req.Role, user.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			NewError(r.Context(), w, http.StatusNotFound, "No user found with this account name", r.URL.Path)
+		case errors.Is(err, database.ErrAmbiguousUsername):
+			NewError(r.Context(), w, http.StatusConflict, "Several users share this account name", r.URL.Path)
+		default:
+			NewInternalError(r.Context(), w, "inviting collaborator", err, r.URL.Path)
+		}
+		return
+	}
+
+	WriteJSON(r.Context(), w, http.StatusCreated, collaborator)
+}
+
+// RemoveCollaboratorHandler lets the owner remove anyone and collaborators
+// remove themselves.
+func (s *Server) RemoveCollaboratorHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := GetUser(r)
+	if err != nil {
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
+		return
+	}
+
+	strategy, ok := s.loadStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	username := r.PathValue("username")
+
+	if strategy.UserID != user.ID && username != user.Name {
+		NewError(r.Context(), w, http.StatusForbidden, "You do not have permission to modify this strategy.", r.URL.Path)
+		return
+	}
+
+	s.removeCollaborator(w, r, strategy.ID, username)
+}
+
+func (s *Server) ListInvitationsHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := GetUser(r)
+	if err != nil {
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
+		return
+	}
+
+	status := models.InvitationPending
+	if r.URL.Query().Get("status") == models.InvitationAccepted {
+		status = models.InvitationAccepted
+	}
+
+	invitations, err := s.db.ListInvitations(r.Context(), user.ID, status)
+	if err != nil {
+		NewInternalError(r.Context(), w, "listing invitations", err, r.URL.Path)
+		return
+	}
+
+	WriteJSON(r.Context(), w, http.StatusOK, invitations)
+}
+
+func (s *Server) AcceptInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := GetUser(r)
+	if err != nil {
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
+		return
+	}
+
+	strategyID, err := PathID(r, "strategy_id")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return
+	}
+
+	if err := s.db.AcceptInvitation(r.Context(), strategyID, user.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			NewError(r.Context(), w, http.StatusNotFound, "No pending invitation for this strategy", r.URL.Path)
+			return
+		}
+
+		NewInternalError(r.Context(), w, "accepting invitation", err, r.URL.Path)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) DeclineInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := GetUser(r)
+	if err != nil {
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
+		return
+	}
+
+	strategyID, err := PathID(r, "strategy_id")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return
+	}
+
+	s.removeCollaborator(w, r, strategyID, user.Name)
+}
+
+func (s *Server) removeCollaborator(w http.ResponseWriter, r *http.Request, strategyID int, username string) {
+	if err := s.db.RemoveCollaborator(r.Context(), strategyID, username); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			NewError(r.Context(), w, http.StatusNotFound, "No collaborator found with this account name", r.URL.Path)
+			return
+		}
+
+		NewInternalError(r.Context(), w, "removing collaborator", err, r.URL.Path)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,266 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+const maxCommentLength = 2000
+
+func validateCommentBody(body string) Errors {
+	errs := Errors{}
+
+	switch {
+	case strings.TrimSpace(body) == "":
+		errs["body"] = "must not be empty"
+	case utf8.RuneCountInString(body) > maxCommentLength:
+		errs["body"] = "must be at most 2000 characters"
+	}
+
+	return errs
+}
+
+func (s *Server) ListCommentsHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.viewableStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	limit, offset := parsePagination(r)
+
+	comments, total, err := s.db.ListComments(r.Context(), strategy.ID, limit, offset)
+	if err != nil {
+		NewInternalError(r.Context(), w, "listing comments", err, r.URL.Path)
+		return
+	}
+
+	result := models.CommentsDTO{Comments: comments, Limit: limit, Offset: offset, Total: total}
+
+	WriteJSON(r.Context(), w, http.StatusOK, result)
+}
+
+func (s *Server) CreateCommentHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.viewableStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	if !strategy.Public {
+		NewError(r.Context(), w, http.StatusForbidden, "Comments are only available on public strategies.", r.URL.Path)
+		return
+	}
+
+	claims, err := GetClaims(r)
+	if err != nil {
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
+		return
+	}
+
+	var req models.CommentRequest
+	statusCode, err := DecodeJSON(r, &req)
+	if err != nil {
+		NewError(r.Context(), w, statusCode, err.Error(), r.URL.Path)
+		return
+	}
+
+	if errs := validateCommentBody(req.Body); len(errs) > 0 {
+		NewFieldErrors(r.Context(), w, http.StatusUnprocessableEntity, "Invalid comment.", errs, r.URL.Path)
+		return
+	}
+
+	comment, err := s.db.StoreComment(r.Context(), models.Comment{
+		StrategyID: strategy.ID,
+		UserID:     claims.UserID,
+		Author:     claims.UserName,
+		ParentID:   req.ParentID,
+		Body:       req.Body,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			NewError(r.Context(), w, http.StatusNotFound, "No parent comment found with this ID", r.URL.Path)
+			return
+		}
+
+		NewInternalError(r.Context(), w, "storing comment", err, r.URL.Path)
+		return
+	}
+
+	WriteJSON(r.Context(), w, http.StatusCreated, comment)
+}
+
+func (s *Server) UpdateCommentHandler(w http.ResponseWriter, r *http.Request) {
+	comment, _, claims, ok := s.loadComment(w, r)
+	if !ok {
+		return
+	}
+
+	if comment.UserID != claims.UserID {
+		NewError(r.Context(), w, http.StatusForbidden, "Only the author can edit this comment.", r.URL.Path)
+		return
+	}
+
+	var req models.CommentRequest
+	statusCode, err := DecodeJSON(r, &req)
+	if err != nil {
+		NewError(r.Context(), w, statusCode, err.Error(), r.URL.Path)
+		return
+	}
+
+	if errs := validateCommentBody(req.Body); len(errs) > 0 {
+		NewFieldErrors(r.Context(), w, http.StatusUnprocessableEntity, "Invalid comment.", errs, r.URL.Path)
+		return
+	}
+
+	updated, err := s.db.UpdateComment(r.Context(), comment.ID, req.Body)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			NewError(r.Context(), w, http.StatusNotFound, "Comment has been deleted", r.URL.Path)
+			return
+		}
+
+		NewInternalError(r.Context(), w, "updating comment", err, r.URL.Path)
+		return
+	}
+
+	WriteJSON(r.Context(), w, http.StatusOK, updated)
+}
+
+// DeleteCommentHandler lets the author, the strategy owner or an admin
+// soft-delete a comment.
+func (s *Server) DeleteCommentHandler(w http.ResponseWriter, r *http.Request) {
+	comment, strategy, claims, ok := s.loadComment(w, r)
+	if !ok {
+		return
+	}
+
+	allowed := comment.UserID == claims.UserID || strategy.UserID == claims.UserID
+
+	if !allowed {
+		role, err := s.db.RetrieveUserRole(r.Context(), claims.UserID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			NewInternalError(r.Context(), w, "retrieving user role", err, r.URL.Path)
+			return
+		}
+		allowed = role == models.RoleAdmin
+	}
+
+	if !allowed {
+		NewError(r.Context(), w, http.StatusForbidden, "You do not have permission to delete this comment.", r.URL.Path)
+		return
+	}
+
+	s.deleteComment(w, r, comment.ID, claims.UserName)
+}
+
+func (s *Server) ModerationCommentsHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.requireAdmin(w, r); !ok {
+		return
+	}
+
+	limit, offset := parsePagination(r)
+	deleted := r.URL.Query().Get("deleted") == "true"
+
+	comments, total, err := s.db.ListRecentComments(r.Context(), deleted, limit, offset)
+	if err != nil {
+		NewInternalError(r.Context(), w, "listing comments", err, r.URL.Path)
+		return
+	}
+
+	result := models.CommentsDTO{Comments: comments, Limit: limit, Offset: offset, Total: total}
+
+	WriteJSON(r.Context(), w, http.StatusOK, result)
+}
+
+func (s *Server) ModerateDeleteCommentHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := s.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	commentID, err := PathID(r, "comment_id")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return
+	}
+
+	s.deleteComment(w, r, commentID, claims.UserName)
+}
+
+func (s *Server) ModerateRestoreCommentHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.requireAdmin(w, r); !ok {
+		return
+	}
+
+	commentID, err := PathID(r, "comment_id")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return
+	}
+
+	if err := s.db.RestoreComment(r.Context(), commentID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			NewError(r.Context(), w, http.StatusNotFound, "No deleted comment found with this ID", r.URL.Path)
+			return
+		}
+
+		NewInternalError(r.Context(), w, "restoring comment", err, r.URL.Path)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) deleteComment(w http.ResponseWriter, r *http.Request, commentID int, deletedBy string) {
+	if err := s.db.DeleteComment(r.Context(), commentID, deletedBy); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			NewError(r.Context(), w, http.StatusNotFound, "No comment found with this ID", r.URL.Path)
+			return
+		}
+
+		NewInternalError(r.Context(), w, "deleting comment", err, r.URL.Path)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadComment authenticates the caller, checks that the strategy from the
+// {strategy_id} path value can still be read and resolves the comment from
+// {comment_id}. Comments of trashed strategies are left to the moderation
+// endpoints.
+func (s *Server) loadComment(w http.ResponseWriter, r *http.Request) (*models.Comment, *models.Strategy, *models.JWTClaims, bool) {
+	claims, err := GetClaims(r)
+	if err != nil {
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
+		return nil, nil, nil, false
+	}
+
+	strategy, ok := s.viewableStrategy(w, r)
+	if !ok {
+		return nil, nil, nil, false
+	}
+
+	commentID, err := PathID(r, "comment_id")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return nil, nil, nil, false
+	}
+
+	comment, err := s.db.RetrieveComment(r.Context(), strategy.ID, commentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			NewError(r.Context(), w, http.StatusNotFound, "No comment found with this ID", r.URL.Path)
+			return nil, nil, nil, false
+		}
+
+		NewInternalError(r.Context(), w, "retrieving comment", err, r.URL.Path)
+		return nil, nil, nil, false
+	}
+
+	return comment, strategy, claims, true
+}
@@ -5,6 +5,16 @@ import (
 	"time"
 
 	"github.com/Vyary/api/internal/models"
+	"github.com/google/uuid"
+)
+
+// The refresh token is only sent to the auth endpoints that exchange or
+// revoke it. Cookies scoped to the refresh endpoint alone, as issued
+// before logout needed them, are expired so they cannot shadow the current
+// one.
+const (
+	refreshCookiePath       = "/auth/poe"
+	legacyRefreshCookiePath = "/auth/poe/refresh"
 )
 
 func setJWTCookies(w http.ResponseWriter, tokenPair models.TokenPair) {
@@ -21,7 +31,7 @@ func setJWTCookies(w http.ResponseWriter, tokenPair models.TokenPair) {
 	jwtRefreshCookie := http.Cookie{
 		Name:     "jwt_refresh",
 		Value:    tokenPair.JWTRefresh,
-		Path:     "/auth/poe/refresh",
+		Path:     refreshCookiePath,
 		Expires:  time.Now().Add(jwtRefreshExpiration),
 		Secure:   true,
 		HttpOnly: true,
@@ -30,6 +40,7 @@ func setJWTCookies(w http.ResponseWriter, tokenPair models.TokenPair) {
 
 	http.SetCookie(w, &jwtCookie)
 	http.SetCookie(w, &jwtRefreshCookie)
+	expireLegacyRefreshCookie(w)
 }
 
 func clearJWTCookies(w http.ResponseWriter) {
@@ -46,7 +57,7 @@ func clearJWTCookies(w http.ResponseWriter) {
 	jwtRefreshCookie := http.Cookie{
 		Name:     "jwt_refresh",
 		Value:    "",
-		Path:     "/auth/poe/refresh",
+		Path:     refreshCookiePath,
 		MaxAge:   -1,
 		Secure:   true,
 		HttpOnly: true,
@@ -55,4 +66,43 @@ func clearJWTCookies(w http.ResponseWriter) {
 
 	http.SetCookie(w, &jwtCookie)
 	http.SetCookie(w, &jwtRefreshCookie)
+	expireLegacyRefreshCookie(w)
+}
+
+func expireLegacyRefreshCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "jwt_refresh",
+		Value:    "",
+		Path:     legacyRefreshCookiePath,
+		MaxAge:   -1,
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// viewerID identifies the reader of a resource, either by user or by an
+// anonymous session cookie that is issued on first visit.
+func viewerID(w http.ResponseWriter, r *http.Request) string {
+	if user, err := GetUser(r); err == nil {
+		return "user:" + user.ID
+	}
+
+	if cookie, err := r.Cookie("session_id"); err == nil && cookie.Value != "" {
+		return "session:" + cookie.Value
+	}
+
+	sessionID := uuid.New().String()
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     "session_id",
+		Value:    sessionID,
+		Path:     "/",
+		Expires:  time.Now().Add(365 * 24 * time.Hour),
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return "session:" + sessionID
 }
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+// revokedTokens is consulted by GetClaims on every authenticated request,
+// so it is a package-level cache like the JWT secret rather than a Server
+// field.
+var revokedTokens = newDenylist()
+
+// denylistInterval is how often the denylist is reloaded to pick up
+// revocations made by other instances.
+const denylistInterval = time.Minute
+
+// denylist caches the ids of revoked access tokens and token families with
+// the time they stop mattering. The database is the source of truth: the
+// cache is loaded before the server starts and reloaded periodically, and
+// revocations made by this instance are added right away.
+type denylist struct {
+	mu  sync.RWMutex
+	ids map[string]int64
+}
+
+func newDenylist() *denylist {
+	return &denylist{ids: make(map[string]int64)}
+}
+
+// contains reports whether any of the ids is revoked.
+func (d *denylist) contains(ids ...string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	now := time.Now().Unix()
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		if expiresAt, ok := d.ids[id]; ok && expiresAt > now {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d *denylist) add(id string, expiresAt int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.ids[id] = max(d.ids[id], expiresAt)
+}
+
+func (d *denylist) replace(ids map[string]int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// revocations made since the load started are not in ids yet
+	now := time.Now().Unix()
+	for id, expiresAt := range d.ids {
+		if _, ok := ids[id]; !ok && expiresAt > now {
+			ids[id] = expiresAt
+		}
+	}
+
+	d.ids = ids
+}
+
+// loadDenylist replaces the cached denylist with the revocations stored in
+// the database.
+func (s *Server) loadDenylist(ctx context.Context) error {
+	revoked, err := s.db.ListRevokedAccessTokens(ctx)
+	if err != nil {
+		return err
+	}
+
+	revokedTokens.replace(revoked)
+
+	return nil
+}
+
+// runDenylist reloads the denylist on every tick until ctx is cancelled.
+// The first load happens in New, before any request is served.
+func (s *Server) runDenylist(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.loadDenylist(ctx); err != nil {
+			CaptureError(ctx, "loading revoked tokens", err)
+		}
+	}
+}
+
+// revokeAccess denies an access token id or a whole token family until
+// expiresAt.
+func (s *Server) revokeAccess(ctx context.Context, id string, expiresAt time.Time) error {
+	if err := s.db.RevokeAccessToken(ctx, id, expiresAt.Unix()); err != nil {
+		return err
+	}
+
+	revokedTokens.add(id, expiresAt.Unix())
+
+	return nil
+}
+
+// revokeSession denies every access token of the family of claims. Tokens
+// issued before families existed are denied by their own id.
+func (s *Server) revokeSession(ctx context.Context, claims *models.JWTClaims) error {
+	if claims.FamilyID == "" {
+		return s.revokeAccess(ctx, claims.TokenID, claims.ExpiresAt.Time)
+	}
+
+	return s.revokeAccess(ctx, claims.FamilyID, time.Now().Add(jwtExpiration))
+}
@@ -12,6 +12,13 @@ import (
 
 type Errors map[string]string
 
+// Merge copies other into e, prefixing every field name.
+func (e Errors) Merge(prefix string, other Errors) {
+	for field, msg := range other {
+		e[prefix+field] = msg
+	}
+}
+
 type AppErr struct {
 	Status   int    `json:"status"`
 	Title    string `json:"title"`
@@ -55,3 +62,24 @@ func NewInternalError(ctx context.Context, w http.ResponseWriter, message string
 	span.SetStatus(codes.Error, message)
 	span.RecordError(err)
 }
+
+func NewError(ctx context.Context, w http.ResponseWriter, status int, details string, path string) {
+	appErr := AppErr{
+		Status:   status,
+		Title:    http.StatusText(status),
+		Details:  details,
+		Instance: path,
+	}
+	WriteJSON(ctx, w, status, appErr)
+}
+
+func NewFieldErrors(ctx context.Context, w http.ResponseWriter, status int, details string, errs Errors, path string) {
+	appErr := AppErr{
+		Status:   status,
+		Title:    http.StatusText(status),
+		Details:  details,
+		Instance: path,
+		Errors:   errs,
+	}
+	WriteJSON(ctx, w, status, appErr)
+}
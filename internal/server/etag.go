@@ -0,0 +1,53 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// setETag exposes the version of a resource as a strong ETag.
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", fmt.Sprintf(`"%d"`, version))
+}
+
+// parseIfMatch reads the version a conditional request expects. A wildcard
+// matches any version and is returned as 0.
+func parseIfMatch(header string) (int, error) {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return 0, nil
+	}
+
+	header = strings.TrimPrefix(header, "W/")
+	version, err := strconv.Atoi(strings.Trim(header, `"`))
+	if err != nil || version <= 0 {
+		return 0, errors.New("If-Match must be an ETag returned by the API")
+	}
+
+	return version, nil
+}
+
+// requireIfMatch rejects writes that do not say which version they are
+// based on. On failure the error response has already been written.
+func requireIfMatch(w http.ResponseWriter, r *http.Request) (int, bool) {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		NewError(r.Context(), w, http.StatusPreconditionRequired, "If-Match header is required.", r.URL.Path)
+		return 0, false
+	}
+
+	version, err := parseIfMatch(header)
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return 0, false
+	}
+
+	return version, true
+}
+
+func writeVersionConflict(w http.ResponseWriter, r *http.Request) {
+	NewError(r.Context(), w, http.StatusPreconditionFailed, "The resource was modified since it was loaded. Reload it and try again.", r.URL.Path)
+}
@@ -0,0 +1,263 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+const maxImportSize = 1 << 20
+
+func (s *Server) ExportStrategyHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.viewableStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	snapshot, err := s.db.RetrieveStrategySnapshot(r.Context(), strategy.ID)
+	if err != nil {
+		NewInternalError(r.Context(), w, "retrieving strategy snapshot", err, r.URL.Path)
+		return
+	}
+
+	tags, err := s.db.RetrieveStrategyTags(r.Context(), strategy.ID)
+	if err != nil {
+		NewInternalError(r.Context(), w, "retrieving strategy tags", err, r.URL.Path)
+		return
+	}
+
+	itemIDs := make([]string, 0)
+	for _, t := range snapshot.Tables {
+		for _, item := range t.Items {
+			itemIDs = append(itemIDs, item.ItemID)
+		}
+	}
+
+	refs, err := s.db.RetrieveItemRefs(r.Context(), itemIDs)
+	if err != nil {
+		NewInternalError(r.Context(), w, "retrieving item refs", err, r.URL.Path)
+		return
+	}
+
+	// document keys replace database ids so the export is self-contained.
+	// Items that are no longer listed, or have neither a name nor a base
+	// type, cannot be referenced and are left out together with pairings to
+	// them, so the document still imports.
+	keys := make(map[int]int)
+	for _, t := range snapshot.Tables {
+		for _, item := range t.Items {
+			if ref := refs[item.ItemID]; ref.Name != "" || ref.BaseType != "" {
+				keys[item.SID] = len(keys) + 1
+			}
+		}
+	}
+
+	doc := models.StrategyDocument{
+		Format:  models.ExportFormat,
+		Version: models.ExportVersion,
+		Strategy: models.ExportStrategy{
+			Name:        snapshot.Name,
+			Description: snapshot.Description,
+			Atlas:       snapshot.Atlas,
+			AtlasTree:   snapshot.AtlasTree,
+			Tags:        tags,
+			Duration:    snapshot.Duration,
+			League:      snapshot.League,
+		},
+		Tables: make([]models.ExportTable, 0, len(snapshot.Tables)),
+	}
+
+	for _, t := range snapshot.Tables {
+		table := models.ExportTable{Type: t.Type, Title: t.Title, Scale: t.Scale, Items: make([]models.ExportItem, 0, len(t.Items))}
+
+		for _, item := range t.Items {
+			if keys[item.SID] == 0 {
+				continue
+			}
+
+			table.Items = append(table.Items, models.ExportItem{
+				Key:           keys[item.SID],
+				Item:          refs[item.ItemID],
+				Amount:        item.Amount,
+				Role:          item.Role,
+				DropChance:    item.DropChance,
+				Pair:          keys[item.Pair],
+				PriceOverride: item.PriceOverride,
+			})
+		}
+
+		doc.Tables = append(doc.Tables, table)
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="strategy-%d.json"`, strategy.ID))
+
+	WriteJSON(r.Context(), w, http.StatusOK, doc)
+}
+
+func (s *Server) ImportStrategyHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := GetUser(r)
+	if err != nil {
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	var doc models.StrategyDocument
+	statusCode, err := DecodeJSON(r, &doc)
+	if err != nil {
+		NewError(r.Context(), w, statusCode, err.Error(), r.URL.Path)
+		return
+	}
+
+	for i := range doc.Tables {
+		if doc.Tables[i].Scale == 0 {
+			doc.Tables[i].Scale = 1
+		}
+	}
+
+	doc.Strategy.Tags = normalizeTags(doc.Strategy.Tags)
+
+	if doc.Strategy.League == "" {
+		doc.Strategy.League = models.LeagueSoftcore
+	}
+
+	if errs := validateDocument(doc); len(errs) > 0 {
+		NewFieldErrors(r.Context(), w, http.StatusUnprocessableEntity, "Invalid strategy document.", errs, r.URL.Path)
+		return
+	}
+
+	resolved := make(map[models.ItemRef]string)
+	unresolved := make([]models.UnresolvedItem, 0)
+	tables := make([]models.SnapshotTable, 0, len(doc.Tables))
+
+	for ti, t := range doc.Tables {
+		table := models.SnapshotTable{
+			StrategyTable: models.StrategyTable{Type: t.Type, Title: t.Title, Scale: t.Scale},
+			Items:         make([]models.StrategyItem, 0, len(t.Items)),
+		}
+
+		for _, item := range t.Items {
+			itemID, ok := resolved[item.Item]
+			if !ok {
+				itemID, err = s.db.ResolveItemRef(r.Context(), item.Item)
+				if err != nil && !errors.Is(err, sql.ErrNoRows) {
+					NewInternalError(r.Context(), w, "resolving item", err, r.URL.Path)
+					return
+				}
+				resolved[item.Item] = itemID
+			}
+
+			if itemID == "" {
+				unresolved = append(unresolved, models.UnresolvedItem{Table: ti, Key: item.Key, Item: item.Item})
+				continue
+			}
+
+			table.Items = append(table.Items, models.StrategyItem{
+				SID:           item.Key,
+				ItemID:        itemID,
+				Amount:        item.Amount,
+				Role:          item.Role,
+				DropChance:    item.DropChance,
+				Pair:          item.Pair,
+				PriceOverride: item.PriceOverride,
+			})
+		}
+
+		tables = append(tables, table)
+	}
+
+	strategy := models.Strategy{
+		Name:        doc.Strategy.Name,
+		Description: doc.Strategy.Description,
+		Atlas:       doc.Strategy.Atlas,
+		AtlasTree:   doc.Strategy.AtlasTree,
+		Tags:        doc.Strategy.Tags,
+		Duration:    doc.Strategy.Duration,
+		League:      doc.Strategy.League,
+	}
+
+	created, err := s.db.CreateStrategyWithTables(r.Context(), *user, strategy, tables)
+	if err != nil {
+		NewInternalError(r.Context(), w, "importing strategy", err, r.URL.Path)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/strategies/%d", created.ID))
+
+	WriteJSON(r.Context(), w, http.StatusCreated, models.ImportResult{Strategy: created.DTO(), Unresolved: unresolved})
+}
+
+// validateDocument checks the structure of an import document and decodes
+// its atlas tree in place. Item references are resolved separately.
+func validateDocument(doc models.StrategyDocument) Errors {
+	errs := Errors{}
+
+	if doc.Format != models.ExportFormat {
+		errs["format"] = fmt.Sprintf("must be %q", models.ExportFormat)
+	}
+
+	if doc.Version < 1 || doc.Version > models.ExportVersion {
+		errs["version"] = fmt.Sprintf("unsupported version %d", doc.Version)
+	}
+
+	errs.Merge("strategy.", validateStrategy(&models.Strategy{
+		Name:        doc.Strategy.Name,
+		Description: doc.Strategy.Description,
+		Atlas:       doc.Strategy.Atlas,
+		AtlasTree:   doc.Strategy.AtlasTree,
+		Duration:    doc.Strategy.Duration,
+		League:      doc.Strategy.League,
+	}))
+	errs.Merge("strategy.", validateTags(doc.Strategy.Tags))
+
+	seen := make(map[int]bool)
+
+	for ti, t := range doc.Tables {
+		errs.Merge(fmt.Sprintf("tables[%d].", ti), validateTable(models.StrategyTable{Type: t.Type, Title: t.Title, Scale: t.Scale}))
+		kind, known := models.LookupTableKind(t.Type)
+
+		keys := make(map[int]bool, len(t.Items))
+		siblings := make([]models.StrategyItem, 0, len(t.Items))
+		for _, item := range t.Items {
+			keys[item.Key] = true
+			siblings = append(siblings, models.StrategyItem{SID: item.Key, Pair: item.Pair, DropChance: item.DropChance})
+		}
+
+		for ii, item := range t.Items {
+			field := fmt.Sprintf("tables[%d].items[%d]", ti, ii)
+
+			switch {
+			case item.Key <= 0:
+				errs[field+".key"] = "must be a positive integer"
+			case seen[item.Key]:
+				errs[field+".key"] = "must be unique within the document"
+			}
+			seen[item.Key] = true
+
+			if item.Item.BaseType == "" && item.Item.Name == "" {
+				errs[field+".item"] = "must reference an item by name or base type"
+			}
+
+			itemErrs := validateItem(models.StrategyItem{Amount: item.Amount, Role: item.Role, DropChance: item.DropChance, PriceOverride: item.PriceOverride})
+			delete(itemErrs, "item_id")
+			errs.Merge(field+".", itemErrs)
+
+			if known {
+				validateItemKind(errs, field+".", models.StrategyItem{Role: item.Role, Pair: item.Pair}, kind)
+			}
+
+			switch {
+			case item.Pair != 0 && !keys[item.Pair]:
+				errs[field+".pair"] = "must reference an item in the same table"
+			case item.Key > 0 && pairGroupChance(siblings[ii], siblings) > maxGroupChance:
+				errs[field+".pair"] = "drop chances of the either-or group must add up to at most 1"
+			}
+		}
+	}
+
+	return errs
+}
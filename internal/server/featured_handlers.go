@@ -0,0 +1,80 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+func (s *Server) ListFeaturedStrategiesHandler(w http.ResponseWriter, r *http.Request) {
+	featured, err := s.db.ListFeaturedStrategies(r.Context())
+	if err != nil {
+		NewInternalError(r.Context(), w, "listing featured strategies", err, r.URL.Path)
+		return
+	}
+
+	WriteJSON(r.Context(), w, http.StatusOK, featured)
+}
+
+func (s *Server) FeatureStrategyHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.requireAdmin(w, r); !ok {
+		return
+	}
+
+	strategyID, err := PathID(r, "strategy_id")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return
+	}
+
+	var req models.FeatureRequest
+	statusCode, err := DecodeJSON(r, &req)
+	if err != nil {
+		NewError(r.Context(), w, statusCode, err.Error(), r.URL.Path)
+		return
+	}
+
+	if req.ExpiresAt != nil && *req.ExpiresAt <= time.Now().Unix() {
+		NewFieldErrors(r.Context(), w, http.StatusUnprocessableEntity, "Invalid feature request.", Errors{"expires_at": "must be in the future"}, r.URL.Path)
+		return
+	}
+
+	if err := s.db.FeatureStrategy(r.Context(), strategyID, req); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			NewError(r.Context(), w, http.StatusNotFound, "No public strategy found with this ID", r.URL.Path)
+			return
+		}
+
+		NewInternalError(r.Context(), w, "featuring strategy", err, r.URL.Path)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) UnfeatureStrategyHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.requireAdmin(w, r); !ok {
+		return
+	}
+
+	strategyID, err := PathID(r, "strategy_id")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return
+	}
+
+	if err := s.db.UnfeatureStrategy(r.Context(), strategyID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			NewError(r.Context(), w, http.StatusNotFound, "No strategy found with this ID", r.URL.Path)
+			return
+		}
+
+		NewInternalError(r.Context(), w, "unfeaturing strategy", err, r.URL.Path)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -27,7 +27,7 @@ func (s *Server) GetItemsHandler() http.Handler {
 		category := r.PathValue("category")
 		search := r.URL.Query().Get("search")
 		order := r.URL.Query().Get("order")
-		league := r.URL.Query().Get("league")
+		league := parseLeague(r)
 
 		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
 		if err != nil {
@@ -39,10 +39,6 @@ func (s *Server) GetItemsHandler() http.Handler {
 			offset = 0
 		}
 
-		if league != "chc" {
-			league = "csc"
-		}
-
 		if order != "asc" {
 			order = "desc"
 		}
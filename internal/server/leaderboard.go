@@ -0,0 +1,154 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Vyary/api/internal/models"
+	"github.com/Vyary/api/internal/profit"
+)
+
+var leagues = []string{"csc", "chc"}
+
+// leaderboard caches the profit per hour ranking of public strategies per
+// league. It is rebuilt in the background instead of on every request since
+// valuing every strategy requires pricing all of their items.
+type leaderboard struct {
+	mu        sync.RWMutex
+	entries   map[string][]models.LeaderboardEntry
+	updatedAt int64
+}
+
+func newLeaderboard() *leaderboard {
+	return &leaderboard{entries: make(map[string][]models.LeaderboardEntry)}
+}
+
+// leaderboardInterval reads LEADERBOARD_INTERVAL, defaulting to 10 minutes.
+func leaderboardInterval() time.Duration {
+	if v := os.Getenv("LEADERBOARD_INTERVAL"); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err == nil && interval > 0 {
+			return interval
+		}
+
+		slog.Warn("invalid LEADERBOARD_INTERVAL, using default", "value", v)
+	}
+
+	return 10 * time.Minute
+}
+
+// runLeaderboard rebuilds the leaderboard immediately and then on every tick
+// until ctx is cancelled.
+func (s *Server) runLeaderboard(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.refreshLeaderboard(ctx); err != nil {
+			CaptureError(ctx, "refreshing leaderboard", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) refreshLeaderboard(ctx context.Context) error {
+	strategies, err := s.db.ListRankableStrategies(ctx)
+	if err != nil {
+		return err
+	}
+
+	entries := make(map[string][]models.LeaderboardEntry, len(leagues))
+
+	for _, strategy := range strategies {
+		tables, err := s.db.RetrieveStrategyTables(ctx, strategy.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, league := range leagues {
+			items, err := s.db.RetrievePricedStrategyItems(ctx, strategy.ID, league)
+			if err != nil {
+				return err
+			}
+
+			report := profit.Calculate(tables, items)
+
+			perHour, ok := profit.PerHour(report.NetProfit, strategy.Duration)
+			if !ok {
+				continue
+			}
+
+			entries[league] = append(entries[league], models.LeaderboardEntry{
+				StrategyDTO:   strategy.DTO(),
+				NetProfit:     report.NetProfit,
+				ProfitPerHour: perHour,
+			})
+		}
+	}
+
+	for _, ranked := range entries {
+		slices.SortStableFunc(ranked, func(a, b models.LeaderboardEntry) int {
+			return cmp.Compare(b.ProfitPerHour, a.ProfitPerHour)
+		})
+	}
+
+	s.leaderboard.mu.Lock()
+	s.leaderboard.entries = entries
+	s.leaderboard.updatedAt = time.Now().Unix()
+	s.leaderboard.mu.Unlock()
+
+	return nil
+}
+
+// rank returns the cached ranking of a league, optionally restricted to an
+// atlas. Ranks are assigned after filtering.
+func (l *leaderboard) rank(league string, atlas string) ([]models.LeaderboardEntry, int64) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	ranked := make([]models.LeaderboardEntry, 0)
+	for _, e := range l.entries[league] {
+		if atlas != "" && e.Atlas != atlas {
+			continue
+		}
+
+		e.Rank = len(ranked) + 1
+		ranked = append(ranked, e)
+	}
+
+	return ranked, l.updatedAt
+}
+
+func (s *Server) LeaderboardHandler(w http.ResponseWriter, r *http.Request) {
+	league := parseLeague(r)
+	atlas := r.URL.Query().Get("atlas")
+	limit, offset := parsePagination(r)
+
+	ranked, updatedAt := s.leaderboard.rank(league, atlas)
+
+	start := min(offset, len(ranked))
+	end := min(offset+limit, len(ranked))
+
+	result := models.Leaderboard{
+		League:    league,
+		Atlas:     atlas,
+		UpdatedAt: updatedAt,
+		Entries:   ranked[start:end],
+		Limit:     limit,
+		Offset:    offset,
+		Total:     len(ranked),
+	}
+
+	WriteJSON(r.Context(), w, http.StatusOK, result)
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+)
+
+func (s *Server) LikeStrategyHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.viewableStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := GetUser(r)
+	if err != nil {
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
+		return
+	}
+
+	if _, err := s.db.LikeStrategy(r.Context(), strategy.ID, user.ID); err != nil {
+		NewInternalError(r.Context(), w, "liking strategy", err, r.URL.Path)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) UnlikeStrategyHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.viewableStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := GetUser(r)
+	if err != nil {
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
+		return
+	}
+
+	if err := s.db.UnlikeStrategy(r.Context(), strategy.ID, user.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			NewError(r.Context(), w, http.StatusNotFound, "Strategy is not liked", r.URL.Path)
+			return
+		}
+
+		NewInternalError(r.Context(), w, "unliking strategy", err, r.URL.Path)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) GetStrategyLikeHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.viewableStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := GetUser(r)
+	if err != nil {
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
+		return
+	}
+
+	liked, err := s.db.IsStrategyLiked(r.Context(), strategy.ID, user.ID)
+	if err != nil {
+		NewInternalError(r.Context(), w, "checking strategy like", err, r.URL.Path)
+		return
+	}
+
+	WriteJSON(r.Context(), w, http.StatusOK, map[string]any{
+		"liked":      liked,
+		"like_count": strategy.LikeCount,
+	})
+}
@@ -33,8 +33,9 @@ func Cors(next http.Handler) http.Handler {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 		}
 
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, If-Match, X-Share-Token")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag, Location")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
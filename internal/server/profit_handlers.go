@@ -0,0 +1,281 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Vyary/api/internal/models"
+	"github.com/Vyary/api/internal/profit"
+)
+
+func (s *Server) StrategyProfitHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.viewableStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	league := strategyLeague(r, strategy)
+
+	tables, err := s.db.RetrieveStrategyTables(r.Context(), strategy.ID)
+	if err != nil {
+		NewInternalError(r.Context(), w, "retrieving strategy tables", err, r.URL.Path)
+		return
+	}
+
+	items, err := s.db.RetrievePricedStrategyItems(r.Context(), strategy.ID, league)
+	if err != nil {
+		NewInternalError(r.Context(), w, "retrieving priced strategy items", err, r.URL.Path)
+		return
+	}
+
+	report := profit.Calculate(tables, items)
+	report.StrategyID = strategy.ID
+	report.League = league
+	report.Duration = strategy.Duration
+
+	if perHour, ok := profit.PerHour(report.NetProfit, strategy.Duration); ok {
+		report.ProfitPerHour = &perHour
+	}
+
+	WriteJSON(r.Context(), w, http.StatusOK, report)
+}
+
+const (
+	maxSimulatedRuns = 1_000_000
+	// maxSimulationSamples bounds the random draws of a simulation, which
+	// runs on the request goroutine.
+	maxSimulationSamples = 10_000_000
+)
+
+func (s *Server) SimulateStrategyHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.viewableStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	league := strategyLeague(r, strategy)
+
+	cfg := profit.SimulationConfig{Runs: 100, Trials: 1000, Bins: 20}
+	errs := Errors{}
+
+	if v := query.Get("runs"); v != "" {
+		runs, err := strconv.Atoi(v)
+		if err != nil || runs <= 0 {
+			errs["runs"] = "must be a positive integer"
+		}
+		cfg.Runs = runs
+	}
+
+	if v := query.Get("trials"); v != "" {
+		trials, err := strconv.Atoi(v)
+		if err != nil || trials <= 0 {
+			errs["trials"] = "must be a positive integer"
+		}
+		cfg.Trials = trials
+	}
+
+	if v := query.Get("bins"); v != "" {
+		bins, err := strconv.Atoi(v)
+		if err != nil || bins <= 0 || bins > 200 {
+			errs["bins"] = "must be between 1 and 200"
+		}
+		cfg.Bins = bins
+	}
+
+	if v := query.Get("seed"); v != "" {
+		seed, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			errs["seed"] = "must be an unsigned integer"
+		}
+		cfg.Seed = seed
+	} else {
+		cfg.Seed = rand.Uint64()
+	}
+
+	if len(errs) == 0 && (cfg.Runs > maxSimulatedRuns || cfg.Trials > maxSimulatedRuns || cfg.Runs*cfg.Trials > maxSimulatedRuns) {
+		errs["trials"] = fmt.Sprintf("runs × trials must not exceed %d", maxSimulatedRuns)
+	}
+
+	if len(errs) > 0 {
+		NewFieldErrors(r.Context(), w, http.StatusBadRequest, "Invalid simulation parameters.", errs, r.URL.Path)
+		return
+	}
+
+	tables, err := s.db.RetrieveStrategyTables(r.Context(), strategy.ID)
+	if err != nil {
+		NewInternalError(r.Context(), w, "retrieving strategy tables", err, r.URL.Path)
+		return
+	}
+
+	items, err := s.db.RetrievePricedStrategyItems(r.Context(), strategy.ID, league)
+	if err != nil {
+		NewInternalError(r.Context(), w, "retrieving priced strategy items", err, r.URL.Path)
+		return
+	}
+
+	if profit.Samples(tables, items, cfg) > maxSimulationSamples {
+		errs["trials"] = fmt.Sprintf("runs × trials × random drops must not exceed %d", maxSimulationSamples)
+		NewFieldErrors(r.Context(), w, http.StatusBadRequest, "Invalid simulation parameters.", errs, r.URL.Path)
+		return
+	}
+
+	report := profit.Simulate(tables, items, cfg)
+	report.StrategyID = strategy.ID
+	report.League = league
+
+	WriteJSON(r.Context(), w, http.StatusOK, report)
+}
+
+const maxBacktestPoints = 2000
+
+func (s *Server) BacktestStrategyHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.viewableStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	league := strategyLeague(r, strategy)
+	errs := Errors{}
+
+	to := time.Now().UTC()
+	if v := query.Get("to"); v != "" {
+		t, err := parseTime(v)
+		if err != nil {
+			errs["to"] = err.Error()
+		}
+		to = t
+	}
+
+	from := to.AddDate(0, 0, -30)
+	if v := query.Get("from"); v != "" {
+		t, err := parseTime(v)
+		if err != nil {
+			errs["from"] = err.Error()
+		}
+		from = t
+	}
+
+	step := 24 * time.Hour
+	if v := query.Get("step"); v != "" {
+		d, err := parseStep(v)
+		if err != nil {
+			errs["step"] = err.Error()
+		}
+		step = d
+	}
+
+	if len(errs) == 0 {
+		switch {
+		case !from.Before(to):
+			errs["from"] = "must be before to"
+		case step < time.Hour:
+			errs["step"] = "must be at least 1h"
+		case to.Sub(from)/step > maxBacktestPoints:
+			errs["step"] = fmt.Sprintf("range yields more than %d points", maxBacktestPoints)
+		}
+	}
+
+	if len(errs) > 0 {
+		NewFieldErrors(r.Context(), w, http.StatusBadRequest, "Invalid backtest parameters.", errs, r.URL.Path)
+		return
+	}
+
+	tables, err := s.db.RetrieveStrategyTables(r.Context(), strategy.ID)
+	if err != nil {
+		NewInternalError(r.Context(), w, "retrieving strategy tables", err, r.URL.Path)
+		return
+	}
+
+	items, err := s.db.RetrievePricedStrategyItems(r.Context(), strategy.ID, league)
+	if err != nil {
+		NewInternalError(r.Context(), w, "retrieving strategy items", err, r.URL.Path)
+		return
+	}
+
+	itemIDs := make([]string, 0, len(items))
+	for _, item := range items {
+		itemIDs = append(itemIDs, item.ItemID)
+	}
+
+	points, err := s.db.RetrievePriceHistory(r.Context(), itemIDs, league, from.Unix(), to.Unix())
+	if err != nil {
+		NewInternalError(r.Context(), w, "retrieving price history", err, r.URL.Path)
+		return
+	}
+
+	// prices are quoted in other currencies, their history is needed to
+	// convert every observation into the base currency
+	seen := make(map[string]bool, len(itemIDs))
+	for _, id := range itemIDs {
+		seen[id] = true
+	}
+
+	currencyIDs := make([]string, 0)
+	for _, p := range points {
+		if p.CurrencyID != "" && !seen[p.CurrencyID] {
+			seen[p.CurrencyID] = true
+			currencyIDs = append(currencyIDs, p.CurrencyID)
+		}
+	}
+
+	rates, err := s.db.RetrievePriceHistory(r.Context(), currencyIDs, league, from.Unix(), to.Unix())
+	if err != nil {
+		NewInternalError(r.Context(), w, "retrieving currency history", err, r.URL.Path)
+		return
+	}
+
+	history := profit.NewHistory(append(points, rates...))
+
+	report := models.BacktestReport{
+		StrategyID: strategy.ID,
+		League:     league,
+		From:       from.Unix(),
+		To:         to.Unix(),
+		Step:       int64(step.Seconds()),
+		Points:     profit.Backtest(tables, items, history, from, to, step),
+	}
+
+	WriteJSON(r.Context(), w, http.StatusOK, report)
+}
+
+// parseTime accepts unix seconds, RFC 3339 timestamps or plain dates.
+func parseTime(v string) (time.Time, error) {
+	if sec, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(sec, 0).UTC(), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.UTC(), nil
+	}
+
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, errors.New("must be unix seconds, RFC 3339 or YYYY-MM-DD")
+}
+
+// parseStep extends time.ParseDuration with a day unit, e.g. "1d".
+func parseStep(v string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(v, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, errors.New("invalid day step")
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, errors.New("must be a duration such as 1d or 6h")
+	}
+
+	return d, nil
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Vyary/api/internal/database"
+	"github.com/Vyary/api/internal/models"
+	"github.com/Vyary/api/internal/revision"
+)
+
+func (s *Server) ListStrategyRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.historyStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	revisions, err := s.db.ListStrategyRevisions(r.Context(), strategy.ID)
+	if err != nil {
+		NewInternalError(r.Context(), w, "listing strategy revisions", err, r.URL.Path)
+		return
+	}
+
+	WriteJSON(r.Context(), w, http.StatusOK, revisions)
+}
+
+func (s *Server) GetStrategyRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.historyStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	revisionID, err := PathID(r, "revision")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return
+	}
+
+	rev, err := s.db.RetrieveStrategyRevision(r.Context(), strategy.ID, revisionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			NewError(r.Context(), w, http.StatusNotFound, "No revision found with this number", r.URL.Path)
+			return
+		}
+
+		NewInternalError(r.Context(), w, "retrieving strategy revision", err, r.URL.Path)
+		return
+	}
+
+	WriteJSON(r.Context(), w, http.StatusOK, rev)
+}
+
+func (s *Server) DiffStrategyRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.historyStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	from, err := PathID(r, "revision")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return
+	}
+
+	to, err := PathID(r, "other")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return
+	}
+
+	snapshots := make([]models.StrategySnapshot, 0, 2)
+	for _, number := range []int{from, to} {
+		rev, err := s.db.RetrieveStrategyRevision(r.Context(), strategy.ID, number)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				NewError(r.Context(), w, http.StatusNotFound, "No revision found with number "+strconv.Itoa(number), r.URL.Path)
+				return
+			}
+
+			NewInternalError(r.Context(), w, "retrieving strategy revision", err, r.URL.Path)
+			return
+		}
+		snapshots = append(snapshots, *rev.Snapshot)
+	}
+
+	diff := revision.Diff(snapshots[0], snapshots[1])
+	diff.StrategyID = strategy.ID
+	diff.From = from
+	diff.To = to
+
+	WriteJSON(r.Context(), w, http.StatusOK, diff)
+}
+
+func (s *Server) RestoreStrategyRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.editableStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	revisionID, err := PathID(r, "revision")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return
+	}
+
+	// restoring overwrites the whole strategy, so it is guarded by its ETag
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := GetUser(r)
+	if err != nil {
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
+		return
+	}
+
+	rev, updated, err := s.db.RestoreStrategyRevision(r.Context(), strategy.ID, revisionID, version, user.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrVersionConflict):
+			writeVersionConflict(w, r)
+		case errors.Is(err, sql.ErrNoRows):
+			NewError(r.Context(), w, http.StatusNotFound, "No revision found with this number", r.URL.Path)
+		default:
+			NewInternalError(r.Context(), w, "restoring strategy revision", err, r.URL.Path)
+		}
+		return
+	}
+
+	setETag(w, updated)
+	WriteJSON(r.Context(), w, http.StatusOK, rev)
+}
+
+// revisionAuthor names the user recorded on the revision of a mutation.
+// Strategies can only be edited by authenticated users.
+func revisionAuthor(r *http.Request) string {
+	user, err := GetUser(r)
+	if err != nil {
+		return ""
+	}
+
+	return user.Name
+}
@@ -1,11 +1,7 @@
 package server
 
 import (
-	"encoding/json"
-	"log/slog"
 	"net/http"
-
-	"github.com/Vyary/api/internal/models"
 )
 
 func (s *Server) RegisterRoutes() http.Handler {
@@ -20,51 +16,95 @@ func (s *Server) RegisterRoutes() http.Handler {
 	mux.HandleFunc("POST /auth/poe/logout", s.LogoutHandler)
 	mux.HandleFunc("POST /auth/poe/logout-all", s.LogoutAllHandler)
 
+	mux.HandleFunc("GET /v1/me/strategies", s.ListMyStrategiesHandler)
+	mux.HandleFunc("GET /v1/me/trash", s.ListTrashHandler)
+	mux.HandleFunc("POST /v1/me/trash/{strategy_id}/restore", s.RestoreStrategyHandler)
+	mux.HandleFunc("DELETE /v1/me/trash/{strategy_id}", s.PurgeStrategyHandler)
+	mux.HandleFunc("GET /v1/me/invitations", s.ListInvitationsHandler)
+	mux.HandleFunc("POST /v1/me/invitations/{strategy_id}/accept", s.AcceptInvitationHandler)
+	mux.HandleFunc("DELETE /v1/me/invitations/{strategy_id}", s.DeclineInvitationHandler)
+	mux.HandleFunc("GET /v1/me/sessions", s.ListSessionsHandler)
+	mux.HandleFunc("DELETE /v1/me/sessions/{session_id}", s.RevokeSessionHandler)
+
 	mux.HandleFunc("POST /v1/strategies", s.CreateStrategyHandler)
-	// mux.HandleFunc("GET /v1/strategies", s.ListPublicStrategiesHandler)
-	// mux.HandleFunc("GET /v1/strategies/featured", s.ListFeaturedStrategiesHandler)
+	mux.HandleFunc("POST /v1/strategies/import", s.ImportStrategyHandler)
+	mux.HandleFunc("GET /v1/strategies", s.ListPublicStrategiesHandler)
+	mux.HandleFunc("GET /v1/strategies/leaderboard", s.LeaderboardHandler)
+	mux.HandleFunc("GET /v1/strategies/table-kinds", s.ListTableKindsHandler)
+	mux.HandleFunc("GET /v1/strategies/tags", s.TagSuggestionsHandler)
+	mux.HandleFunc("GET /v1/strategies/featured", s.ListFeaturedStrategiesHandler)
+
+	mux.HandleFunc("GET /v1/templates", s.ListTemplatesHandler)
+	mux.HandleFunc("GET /v1/templates/{template_id}", s.GetTemplateHandler)
 
 	mux.HandleFunc("GET /v1/strategies/{strategy_id}", s.GetStrategyHandler)
-	// mux.HandleFunc("PUT /v1/strategies/{strategy_id}", s.UpdateStrategyHandler)
-	// mux.HandleFunc("DELETE /v1/strategies/{strategy_id}", s.DeleteStrategyHandler)
+	mux.HandleFunc("PUT /v1/strategies/{strategy_id}", s.UpdateStrategyHandler)
+	mux.HandleFunc("DELETE /v1/strategies/{strategy_id}", s.DeleteStrategyHandler)
+	mux.HandleFunc("POST /v1/strategies/{strategy_id}/fork", s.ForkStrategyHandler)
+	mux.HandleFunc("GET /v1/strategies/{strategy_id}/export", s.ExportStrategyHandler)
+	mux.HandleFunc("PUT /v1/strategies/{strategy_id}/tags", s.SetStrategyTagsHandler)
+
+	mux.HandleFunc("GET /v1/strategies/{strategy_id}/collaborators", s.ListCollaboratorsHandler)
+	mux.HandleFunc("POST /v1/strategies/{strategy_id}/collaborators", s.InviteCollaboratorHandler)
+	mux.HandleFunc("DELETE /v1/strategies/{strategy_id}/collaborators/{username}", s.RemoveCollaboratorHandler)
+
+	mux.HandleFunc("GET /v1/strategies/{strategy_id}/share-links", s.ListShareLinksHandler)
+	mux.HandleFunc("POST /v1/strategies/{strategy_id}/share-links", s.CreateShareLinkHandler)
+	mux.HandleFunc("DELETE /v1/strategies/{strategy_id}/share-links/{link_id}", s.RevokeShareLinkHandler)
+
+	mux.HandleFunc("GET /v1/strategies/{strategy_id}/like", s.GetStrategyLikeHandler)
+	mux.HandleFunc("PUT /v1/strategies/{strategy_id}/like", s.LikeStrategyHandler)
+	mux.HandleFunc("DELETE /v1/strategies/{strategy_id}/like", s.UnlikeStrategyHandler)
+
+	mux.HandleFunc("GET /v1/strategies/{strategy_id}/profit", s.StrategyProfitHandler)
+	mux.HandleFunc("GET /v1/strategies/{strategy_id}/simulation", s.SimulateStrategyHandler)
+	mux.HandleFunc("GET /v1/strategies/{strategy_id}/backtest", s.BacktestStrategyHandler)
+
+	mux.HandleFunc("GET /v1/strategies/{strategy_id}/runs", s.ListRunsHandler)
+	mux.HandleFunc("POST /v1/strategies/{strategy_id}/runs", s.RecordRunHandler)
+	mux.HandleFunc("GET /v1/strategies/{strategy_id}/runs/report", s.RunReportHandler)
+	mux.HandleFunc("DELETE /v1/strategies/{strategy_id}/runs/{run_id}", s.DeleteRunHandler)
+
+	mux.HandleFunc("GET /v1/strategies/{strategy_id}/comments", s.ListCommentsHandler)
+	mux.HandleFunc("POST /v1/strategies/{strategy_id}/comments", s.CreateCommentHandler)
+	mux.HandleFunc("PUT /v1/strategies/{strategy_id}/comments/{comment_id}", s.UpdateCommentHandler)
+	mux.HandleFunc("DELETE /v1/strategies/{strategy_id}/comments/{comment_id}", s.DeleteCommentHandler)
+
+	mux.HandleFunc("GET /v1/strategies/{strategy_id}/revisions", s.ListStrategyRevisionsHandler)
+	mux.HandleFunc("GET /v1/strategies/{strategy_id}/revisions/{revision}", s.GetStrategyRevisionHandler)
+	mux.HandleFunc("GET /v1/strategies/{strategy_id}/revisions/{revision}/diff/{other}", s.DiffStrategyRevisionsHandler)
+	mux.HandleFunc("POST /v1/strategies/{strategy_id}/revisions/{revision}/restore", s.RestoreStrategyRevisionHandler)
 
 	mux.HandleFunc("POST /v1/strategies/{strategy_id}/tables", s.CreateStrategyTableHandler)
-	// mux.HandleFunc("GET /v1/strategies/{strategy_id}/tables", s.ListStrategyTablesHandler)
-	// mux.HandleFunc("PUT /v1/strategies/{strategy_id}/tables/{table_id}", s.UpdateStrategyTableHandler)
-	// mux.HandleFunc("DELETE /v1/strategies/{strategy_id}/tables/{table_id}", s.DeleteStrategyTableHandler)
+	mux.HandleFunc("GET /v1/strategies/{strategy_id}/tables", s.ListStrategyTablesHandler)
+	mux.HandleFunc("PUT /v1/strategies/{strategy_id}/tables/{table_id}", s.UpdateStrategyTableHandler)
+	mux.HandleFunc("DELETE /v1/strategies/{strategy_id}/tables/{table_id}", s.DeleteStrategyTableHandler)
 
 	mux.HandleFunc("POST /v1/strategies/{strategy_id}/items", s.AddStrategyItemHandler)
-	// mux.HandleFunc("GET /v1/strategies/{strategy_id}/tables/{table_id}/items", s.ListStrategyItemHandler)
-	// mux.HandleFunc("PUT /v1/strategies/{strategy_id}/tables/{table_id}/items/{item_id}", s.UpdateStrategyItemHandler)
-	// mux.HandleFunc("DELETE /v1/strategies/{strategy_id}/tables/{table_id}/items/{item_id}", s.DeleteStrategyItemHandler)
-
-	return Cors(CompressMiddleware(TraceMiddleware(LogMiddleware(mux))))
-}
+	mux.HandleFunc("GET /v1/strategies/{strategy_id}/tables/{table_id}/items", s.ListStrategyItemHandler)
+	mux.HandleFunc("PUT /v1/strategies/{strategy_id}/tables/{table_id}/items/{item_id}", s.UpdateStrategyItemHandler)
+	mux.HandleFunc("DELETE /v1/strategies/{strategy_id}/tables/{table_id}/items/{item_id}", s.DeleteStrategyItemHandler)
 
-func (s *Server) AddStrategyItemHandler(w http.ResponseWriter, r *http.Request) {
-	strategyID := r.PathValue("strategy_id")
+	mux.HandleFunc("PUT /v1/admin/strategies/{strategy_id}/featured", s.FeatureStrategyHandler)
+	mux.HandleFunc("DELETE /v1/admin/strategies/{strategy_id}/featured", s.UnfeatureStrategyHandler)
 
-	// user, err := GetUser(r)
-	// if err != nil {
-	// 	writeError(w, http.StatusUnauthorized, err.Error())
-	// 	return
-	// }
+	mux.HandleFunc("GET /v1/admin/comments", s.ModerationCommentsHandler)
+	mux.HandleFunc("DELETE /v1/admin/comments/{comment_id}", s.ModerateDeleteCommentHandler)
+	mux.HandleFunc("POST /v1/admin/comments/{comment_id}/restore", s.ModerateRestoreCommentHandler)
 
-	var strategyItem models.StrategyItem
-	if err := json.NewDecoder(r.Body).Decode(&strategyItem); err != nil {
-		slog.Error("failed to decode request body", "error", err)
-		writeError(w, http.StatusBadRequest, "Invalid JSON format in request body")
-		return
-	}
+	mux.HandleFunc("POST /v1/admin/templates", s.CreateTemplateHandler)
+	mux.HandleFunc("PUT /v1/admin/templates/{template_id}", s.UpdateTemplateHandler)
+	mux.HandleFunc("DELETE /v1/admin/templates/{template_id}", s.DeleteTemplateHandler)
 
-	// TODO: validate strategy item
-	// TODO: check if user can add items to strategy
+	// from-template/{template_id} has the same shape as the per-strategy
+	// routes such as {strategy_id}/fork, which ServeMux rejects as a
+	// conflict, so it is served by a mux of its own.
+	templates := http.NewServeMux()
+	templates.HandleFunc("POST /v1/strategies/from-template/{template_id}", s.CreateStrategyFromTemplateHandler)
 
-	if err := s.db.StoreStrategyItem(strategyID, strategyItem); err != nil {
-		slog.Error("failed to store strategy item", "error", err)
-		writeError(w, http.StatusInternalServerError, "Unable to save strategy item, try again later.")
-		return
-	}
+	routes := http.NewServeMux()
+	routes.Handle("/", mux)
+	routes.Handle("/v1/strategies/from-template/", templates)
 
-	w.WriteHeader(http.StatusOK)
+	return Cors(CompressMiddleware(TraceMiddleware(LogMiddleware(routes))))
 }
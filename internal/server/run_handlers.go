@@ -0,0 +1,229 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Vyary/api/internal/models"
+	"github.com/Vyary/api/internal/profit"
+)
+
+const (
+	maxRunDuration    = 24 * 60 * 60
+	maxRunNotesLength = 1000
+)
+
+// validateRun checks the run request against the items of the strategy,
+// keyed by their ID.
+func validateRun(req models.RunRequest, items map[int]models.PricedStrategyItem) Errors {
+	errs := Errors{}
+
+	if req.Duration <= 0 || req.Duration > maxRunDuration {
+		errs["duration"] = fmt.Sprintf("must be between 1 and %d seconds", maxRunDuration)
+	}
+
+	validateLength(errs, "notes", req.Notes, maxRunNotesLength)
+
+	seen := make(map[int]bool, len(req.Items))
+	for i, item := range req.Items {
+		field := fmt.Sprintf("items[%d]", i)
+
+		if _, ok := items[item.StrategyItemID]; !ok {
+			errs[field+".strategy_item_id"] = "does not belong to this strategy"
+		} else if seen[item.StrategyItemID] {
+			errs[field+".strategy_item_id"] = "is listed more than once"
+		}
+		seen[item.StrategyItemID] = true
+
+		if item.Quantity < 0 {
+			errs[field+".quantity"] = "must not be negative"
+		}
+	}
+
+	return errs
+}
+
+func (s *Server) ListRunsHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.viewableStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	limit, offset := parsePagination(r)
+
+	runs, total, err := s.db.ListRuns(r.Context(), strategy.ID, limit, offset)
+	if err != nil {
+		NewInternalError(r.Context(), w, "listing runs", err, r.URL.Path)
+		return
+	}
+
+	result := models.RunsDTO{Runs: runs, Limit: limit, Offset: offset, Total: total}
+
+	WriteJSON(r.Context(), w, http.StatusOK, result)
+}
+
+// RecordRunHandler stores a completed run. Strategy items missing from the
+// request are recorded at their declared amount for inputs and as not
+// obtained for outputs.
+func (s *Server) RecordRunHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.viewableStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	claims, err := GetClaims(r)
+	if err != nil {
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
+		return
+	}
+
+	var req models.RunRequest
+	statusCode, err := DecodeJSON(r, &req)
+	if err != nil {
+		NewError(r.Context(), w, statusCode, err.Error(), r.URL.Path)
+		return
+	}
+
+	switch req.League {
+	case "":
+		req.League = "csc"
+	case "csc", "chc":
+	default:
+		NewFieldErrors(r.Context(), w, http.StatusUnprocessableEntity, "Invalid run.", Errors{"league": "must be csc or chc"}, r.URL.Path)
+		return
+	}
+
+	items, err := s.db.RetrievePricedStrategyItems(r.Context(), strategy.ID, req.League)
+	if err != nil {
+		NewInternalError(r.Context(), w, "retrieving strategy items", err, r.URL.Path)
+		return
+	}
+
+	byID := make(map[int]models.PricedStrategyItem, len(items))
+	for _, item := range items {
+		byID[item.SID] = item
+	}
+
+	if errs := validateRun(req, byID); len(errs) > 0 {
+		NewFieldErrors(r.Context(), w, http.StatusUnprocessableEntity, "Invalid run.", errs, r.URL.Path)
+		return
+	}
+
+	reported := make(map[int]bool, len(req.Items))
+	for _, item := range req.Items {
+		reported[item.StrategyItemID] = true
+	}
+
+	for _, item := range items {
+		if reported[item.SID] {
+			continue
+		}
+
+		quantity := 0
+		if item.Role == models.ItemRoleInput {
+			quantity = item.Amount
+		}
+
+		req.Items = append(req.Items, models.RunItem{StrategyItemID: item.SID, Quantity: quantity})
+	}
+
+	run, err := s.db.StoreRun(r.Context(), models.StrategyRun{
+		StrategyID: strategy.ID,
+		UserID:     claims.UserID,
+		Username:   claims.UserName,
+		League:     req.League,
+		Duration:   req.Duration,
+		Notes:      req.Notes,
+		Items:      req.Items,
+	})
+	if err != nil {
+		NewInternalError(r.Context(), w, "storing run", err, r.URL.Path)
+		return
+	}
+
+	WriteJSON(r.Context(), w, http.StatusCreated, run)
+}
+
+// DeleteRunHandler lets the user who recorded a run, or the strategy owner,
+// remove it.
+func (s *Server) DeleteRunHandler(w http.ResponseWriter, r *http.Request) {
+	claims, err := GetClaims(r)
+	if err != nil {
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
+		return
+	}
+
+	strategy, ok := s.loadStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	runID, err := PathID(r, "run_id")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return
+	}
+
+	run, err := s.db.RetrieveRun(r.Context(), strategy.ID, runID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			NewError(r.Context(), w, http.StatusNotFound, "No run found with this ID", r.URL.Path)
+			return
+		}
+
+		NewInternalError(r.Context(), w, "retrieving run", err, r.URL.Path)
+		return
+	}
+
+	if run.UserID != claims.UserID && strategy.UserID != claims.UserID {
+		NewError(r.Context(), w, http.StatusForbidden, "Only the recorder or the strategy owner can delete this run.", r.URL.Path)
+		return
+	}
+
+	if err := s.db.DeleteRun(r.Context(), strategy.ID, run.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			NewError(r.Context(), w, http.StatusNotFound, "No run found with this ID", r.URL.Path)
+			return
+		}
+
+		NewInternalError(r.Context(), w, "deleting run", err, r.URL.Path)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) RunReportHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.viewableStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	league := strategyLeague(r, strategy)
+
+	tables, err := s.db.RetrieveStrategyTables(r.Context(), strategy.ID)
+	if err != nil {
+		NewInternalError(r.Context(), w, "retrieving strategy tables", err, r.URL.Path)
+		return
+	}
+
+	items, err := s.db.RetrievePricedStrategyItems(r.Context(), strategy.ID, league)
+	if err != nil {
+		NewInternalError(r.Context(), w, "retrieving priced strategy items", err, r.URL.Path)
+		return
+	}
+
+	totals, err := s.db.RetrieveRunTotals(r.Context(), strategy.ID, league)
+	if err != nil {
+		NewInternalError(r.Context(), w, "retrieving run totals", err, r.URL.Path)
+		return
+	}
+
+	report := profit.CompareRuns(tables, items, *totals)
+	report.StrategyID = strategy.ID
+	report.League = league
+
+	WriteJSON(r.Context(), w, http.StatusOK, report)
+}
@@ -2,6 +2,7 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -13,11 +14,15 @@ import (
 )
 
 type Server struct {
-	port string
-	db   database.Service
+	port           string
+	db             database.Service
+	leaderboard    *leaderboard
+	trashRetention time.Duration
 }
 
-func New(db database.Service) *http.Server {
+// New builds the HTTP server. Background jobs such as the leaderboard
+// refresh run until ctx is cancelled.
+func New(ctx context.Context, db database.Service) *http.Server {
 	port := os.Getenv("PORT")
 	if port == "" {
 		slog.Error("PORT env variable is required")
@@ -25,10 +30,22 @@ func New(db database.Service) *http.Server {
 	}
 
 	srv := &Server{
-		port: port,
-		db:   db,
+		port:           port,
+		db:             db,
+		leaderboard:    newLeaderboard(),
+		trashRetention: trashRetention(),
 	}
 
+	// revoked tokens must be denied from the first request on
+	if err := srv.loadDenylist(ctx); err != nil {
+		slog.Error("failed to load revoked tokens", "error", err)
+		os.Exit(1)
+	}
+
+	go srv.runLeaderboard(ctx, leaderboardInterval())
+	go srv.runTrashPurge(ctx, time.Hour)
+	go srv.runDenylist(ctx, denylistInterval)
+
 	return &http.Server{
 		Addr:              fmt.Sprintf(":%s", srv.port),
 		Handler:           srv.RegisterRoutes(),
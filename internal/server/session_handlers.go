@@ -0,0 +1,99 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+const maxUserAgentLength = 256
+
+// sessionClient describes the client of a request for the session list. The
+// address is informational only, so the forwarded header set by the proxy is
+// trusted as is.
+func sessionClient(r *http.Request) models.SessionClient {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		ip = strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+
+	userAgent := r.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	return models.SessionClient{UserAgent: userAgent, IP: ip}
+}
+
+func (s *Server) ListSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	claims, err := GetClaims(r)
+	if err != nil {
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
+		return
+	}
+
+	sessions, err := s.db.ListSessions(r.Context(), claims.UserID)
+	if err != nil {
+		NewInternalError(r.Context(), w, "listing sessions", err, r.URL.Path)
+		return
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == claims.FamilyID
+	}
+
+	WriteJSON(r.Context(), w, http.StatusOK, sessions)
+}
+
+// RevokeSessionHandler logs a single device out: its refresh tokens are
+// deleted and the access tokens it still holds are denied.
+func (s *Server) RevokeSessionHandler(w http.ResponseWriter, r *http.Request) {
+	claims, err := GetClaims(r)
+	if err != nil {
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
+		return
+	}
+
+	sessionID := r.PathValue("session_id")
+
+	sessions, err := s.db.ListSessions(r.Context(), claims.UserID)
+	if err != nil {
+		NewInternalError(r.Context(), w, "listing sessions", err, r.URL.Path)
+		return
+	}
+
+	found := false
+	for _, session := range sessions {
+		if session.ID == sessionID {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		NewError(r.Context(), w, http.StatusNotFound, "No session found with this ID", r.URL.Path)
+		return
+	}
+
+	if err := s.db.RevokeRefreshTokenFamily(r.Context(), claims.UserID, sessionID); err != nil {
+		NewInternalError(r.Context(), w, "revoking session", err, r.URL.Path)
+		return
+	}
+
+	if err := s.revokeAccess(r.Context(), sessionID, time.Now().Add(jwtExpiration)); err != nil {
+		NewInternalError(r.Context(), w, "revoking session", err, r.URL.Path)
+		return
+	}
+
+	if sessionID == claims.FamilyID {
+		clearJWTCookies(w)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+// shareToken reads a share token from the share query parameter or the
+// X-Share-Token header.
+func shareToken(r *http.Request) string {
+	if token := r.URL.Query().Get("share"); token != "" {
+		return token
+	}
+
+	return r.Header.Get("X-Share-Token")
+}
+
+func newShareToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (s *Server) CreateShareLinkHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.ownedStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.ShareLinkRequest
+	if r.ContentLength != 0 {
+		statusCode, err := DecodeJSON(r, &req)
+		if err != nil {
+			NewError(r.Context(), w, statusCode, err.Error(), r.URL.Path)
+			return
+		}
+	}
+
+	if req.ExpiresAt != nil && *req.ExpiresAt <= time.Now().Unix() {
+		NewFieldErrors(r.Context(), w, http.StatusUnprocessableEntity, "Invalid share link.", Errors{"expires_at": "must be in the future"}, r.URL.Path)
+		return
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		NewInternalError(r.Context(), w, "generating share token", err, r.URL.Path)
+		return
+	}
+
+	link, err := s.db.StoreShareLink(r.Context(), strategy.ID, token, req.ExpiresAt)
+	if err != nil {
+		NewInternalError(r.Context(), w, "storing share link", err, r.URL.Path)
+		return
+	}
+
+	WriteJSON(r.Context(), w, http.StatusCreated, link)
+}
+
+func (s *Server) ListShareLinksHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.ownedStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	links, err := s.db.ListShareLinks(r.Context(), strategy.ID)
+	if err != nil {
+		NewInternalError(r.Context(), w, "listing share links", err, r.URL.Path)
+		return
+	}
+
+	WriteJSON(r.Context(), w, http.StatusOK, links)
+}
+
+func (s *Server) RevokeShareLinkHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.ownedStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	linkID, err := PathID(r, "link_id")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return
+	}
+
+	if err := s.db.RevokeShareLink(r.Context(), strategy.ID, linkID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			NewError(r.Context(), w, http.StatusNotFound, "No active share link found with this ID", r.URL.Path)
+			return
+		}
+
+		NewInternalError(r.Context(), w, "revoking share link", err, r.URL.Path)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -2,102 +2,510 @@ package server
 
 import (
 	"database/sql"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"log/slog"
 	"net/http"
+	"slices"
 
+	"github.com/Vyary/api/internal/database"
 	"github.com/Vyary/api/internal/models"
 )
 
+type StrategiesDTO struct {
+	Strategies []models.StrategyDTO `json:"strategies"`
+	Limit      int                  `json:"limit"`
+	Offset     int                  `json:"offset"`
+	Total      int                  `json:"total"`
+}
+
 func (s *Server) CreateStrategyHandler(w http.ResponseWriter, r *http.Request) {
 	var strategy models.Strategy
 	statusCode, err := DecodeJSON(r, &strategy)
 	if err != nil {
-		writeError(w, statusCode, err.Error())
+		NewError(r.Context(), w, statusCode, err.Error(), r.URL.Path)
 		return
 	}
 
-	// TODO: Validate strategy
+	if errs := validateStrategy(&strategy); len(errs) > 0 {
+		NewFieldErrors(r.Context(), w, http.StatusUnprocessableEntity, "Invalid strategy.", errs, r.URL.Path)
+		return
+	}
 
 	user, err := GetUser(r)
 	if err != nil {
-		writeError(w, http.StatusUnauthorized, err.Error())
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
 		return
 	}
 
-	storedStrategy, err := s.db.StoreStrategy(*user, strategy)
+	storedStrategy, err := s.db.StoreStrategy(r.Context(), *user, strategy)
 	if err != nil {
-		slog.Error("failed to store strategy", "error", err)
-		writeError(w, http.StatusInternalServerError, "Unable to save strategy, try again later.")
+		NewInternalError(r.Context(), w, "storing strategy", err, r.URL.Path)
 		return
 	}
 
-	w.Header().Set("Access-Control-Expose-Headers", "Location")
 	w.Header().Set("Location", fmt.Sprintf("/strategies/%d", storedStrategy.ID))
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
+	setETag(w, storedStrategy.Version)
 
-	if err = json.NewEncoder(w).Encode(storedStrategy); err != nil {
-		slog.Error("failed to encode success response", "error", err)
-	}
+	WriteJSON(r.Context(), w, http.StatusCreated, storedStrategy)
 }
 
 func (s *Server) GetStrategyHandler(w http.ResponseWriter, r *http.Request) {
-	strategyID := r.PathValue("strategy_id")
+	strategy, ok := s.viewableStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	tags, err := s.db.RetrieveStrategyTags(r.Context(), strategy.ID)
+	if err != nil {
+		NewInternalError(r.Context(), w, "retrieving strategy tags", err, r.URL.Path)
+		return
+	}
+	strategy.Tags = tags
+
+	if err := s.db.RecordStrategyView(r.Context(), strategy.ID, viewerID(w, r)); err != nil {
+		CaptureError(r.Context(), "recording strategy view", err)
+	}
+
+	setETag(w, strategy.Version)
+	WriteJSON(r.Context(), w, http.StatusOK, strategy.DTO())
+}
+
+func (s *Server) ForkStrategyHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, user, ok := s.forkableStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	fork, err := s.db.ForkStrategy(r.Context(), strategy.ID, *user)
+	if err != nil {
+		NewInternalError(r.Context(), w, "forking strategy", err, r.URL.Path)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/strategies/%d", fork.ID))
+
+	WriteJSON(r.Context(), w, http.StatusCreated, fork.DTO())
+}
 
+func (s *Server) ListPublicStrategiesHandler(w http.ResponseWriter, r *http.Request) {
+	s.listStrategies(w, r, parseStrategyQuery(r))
+}
+
+func (s *Server) ListMyStrategiesHandler(w http.ResponseWriter, r *http.Request) {
 	user, err := GetUser(r)
 	if err != nil {
-		writeError(w, http.StatusUnauthorized, err.Error())
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
 		return
 	}
 
-	strategy, err := s.db.RetrieveStrategy(strategyID)
+	q := parseStrategyQuery(r)
+	q.UserID = user.ID
+
+	s.listStrategies(w, r, q)
+}
+
+func (s *Server) listStrategies(w http.ResponseWriter, r *http.Request, q models.StrategyQuery) {
+	strategies, total, err := s.db.ListStrategies(r.Context(), q)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			writeError(w, http.StatusNotFound, "No strategy found with this ID")
-			return
+		NewInternalError(r.Context(), w, "listing strategies", err, r.URL.Path)
+		return
+	}
+
+	result := StrategiesDTO{Strategies: strategies, Limit: q.Limit, Offset: q.Offset, Total: total}
+
+	WriteJSON(r.Context(), w, http.StatusOK, result)
+}
+
+func parseStrategyQuery(r *http.Request) models.StrategyQuery {
+	query := r.URL.Query()
+	limit, offset := parsePagination(r)
+
+	tags := make([]string, 0, len(query["tag"]))
+	for _, tag := range query["tag"] {
+		if tag = normalizeTagName(tag); tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
 		}
+	}
 
-		slog.Error("failed to retrieve strategy", "error", err)
-		writeError(w, http.StatusInternalServerError, "Unable to retrieve strategy")
+	return models.StrategyQuery{
+		Search:    query.Get("search"),
+		Atlas:     query.Get("atlas"),
+		Tags:      tags,
+		CreatedBy: query.Get("creator"),
+		Sort:      query.Get("sort"),
+		Limit:     limit,
+		Offset:    offset,
+	}
+}
+
+func (s *Server) UpdateStrategyHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.editableStrategy(w, r)
+	if !ok {
 		return
 	}
 
-	if !strategy.Public && strategy.UserID != user.ID {
-		writeError(w, http.StatusUnauthorized, "Strategy is private.")
+	version, ok := requireIfMatch(w, r)
+	if !ok {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
+	var update models.Strategy
+	statusCode, err := DecodeJSON(r, &update)
+	if err != nil {
+		NewError(r.Context(), w, statusCode, err.Error(), r.URL.Path)
+		return
+	}
+
+	if errs := validateStrategy(&update); len(errs) > 0 {
+		NewFieldErrors(r.Context(), w, http.StatusUnprocessableEntity, "Invalid strategy.", errs, r.URL.Path)
+		return
+	}
 
-	if err := json.NewEncoder(w).Encode(strategy); err != nil {
-		slog.Error("failed to encode response", "error", err)
+	update.ID = strategy.ID
+	update.Version = version
+
+	// visibility stays under the owner's control
+	if user, err := GetUser(r); err != nil || user.ID != strategy.UserID {
+		update.Public = strategy.Public
+	}
+
+	updated, err := s.db.UpdateStrategy(r.Context(), update, revisionAuthor(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrVersionConflict):
+			writeVersionConflict(w, r)
+		case errors.Is(err, sql.ErrNoRows):
+			NewError(r.Context(), w, http.StatusNotFound, "No strategy found with this ID", r.URL.Path)
+		default:
+			NewInternalError(r.Context(), w, "updating strategy", err, r.URL.Path)
+		}
+		return
 	}
+
+	setETag(w, updated.Version)
+	WriteJSON(r.Context(), w, http.StatusOK, updated.DTO())
+}
+
+func (s *Server) DeleteStrategyHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.ownedStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.db.DeleteStrategy(r.Context(), strategy.ID, version); err != nil {
+		switch {
+		case errors.Is(err, database.ErrVersionConflict):
+			writeVersionConflict(w, r)
+		case errors.Is(err, sql.ErrNoRows):
+			NewError(r.Context(), w, http.StatusNotFound, "No strategy found with this ID", r.URL.Path)
+		default:
+			NewInternalError(r.Context(), w, "deleting strategy", err, r.URL.Path)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListTableKindsHandler lists the table types an editor can offer together
+// with how their items are valued.
+func (s *Server) ListTableKindsHandler(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(r.Context(), w, http.StatusOK, models.TableKinds)
 }
 
 func (s *Server) CreateStrategyTableHandler(w http.ResponseWriter, r *http.Request) {
-	strategyID := r.PathValue("strategy_id")
+	strategy, ok := s.editableStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	var table models.StrategyTable
+	statusCode, err := DecodeJSON(r, &table)
+	if err != nil {
+		NewError(r.Context(), w, statusCode, err.Error(), r.URL.Path)
+		return
+	}
+
+	normalizeTable(&table)
+
+	if errs := validateTable(table); len(errs) > 0 {
+		NewFieldErrors(r.Context(), w, http.StatusUnprocessableEntity, "Invalid table.", errs, r.URL.Path)
+		return
+	}
+
+	storedTable, err := s.db.StoreStrategyTable(r.Context(), strategy.ID, table, revisionAuthor(r))
+	if err != nil {
+		NewInternalError(r.Context(), w, "storing strategy table", err, r.URL.Path)
+		return
+	}
+
+	setETag(w, storedTable.Version)
+	WriteJSON(r.Context(), w, http.StatusCreated, storedTable)
+}
+
+func (s *Server) ListStrategyTablesHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.viewableStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	tables, err := s.db.RetrieveStrategyTables(r.Context(), strategy.ID)
+	if err != nil {
+		NewInternalError(r.Context(), w, "retrieving strategy tables", err, r.URL.Path)
+		return
+	}
+
+	WriteJSON(r.Context(), w, http.StatusOK, tables)
+}
+
+func (s *Server) UpdateStrategyTableHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.editableStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	tableID, err := PathID(r, "table_id")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return
+	}
+
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
 
 	var table models.StrategyTable
-	if err := json.NewDecoder(r.Body).Decode(&table); err != nil {
-		slog.Error("failed to decode request body", "error", err)
-		writeError(w, http.StatusBadRequest, "Invalid JSON format in request body")
+	statusCode, err := DecodeJSON(r, &table)
+	if err != nil {
+		NewError(r.Context(), w, statusCode, err.Error(), r.URL.Path)
+		return
+	}
+
+	normalizeTable(&table)
+
+	if errs := validateTable(table); len(errs) > 0 {
+		NewFieldErrors(r.Context(), w, http.StatusUnprocessableEntity, "Invalid table.", errs, r.URL.Path)
+		return
+	}
+
+	table.ID = tableID
+	table.StrategyID = strategy.ID
+	table.Version = version
+
+	updated, err := s.db.UpdateStrategyTable(r.Context(), table, revisionAuthor(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrVersionConflict):
+			writeVersionConflict(w, r)
+		case errors.Is(err, sql.ErrNoRows):
+			NewError(r.Context(), w, http.StatusNotFound, "No table found with this ID", r.URL.Path)
+		default:
+			NewInternalError(r.Context(), w, "updating strategy table", err, r.URL.Path)
+		}
 		return
 	}
 
-	storedTable, err := s.db.StoreStrategyTable(strategyID, table)
+	setETag(w, updated.Version)
+	WriteJSON(r.Context(), w, http.StatusOK, updated)
+}
+
+func (s *Server) DeleteStrategyTableHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.editableStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	tableID, err := PathID(r, "table_id")
 	if err != nil {
-		slog.Error("failed to store strategy table", "error", err)
-		writeError(w, http.StatusInternalServerError, "Unable to save strategy table, try again later.")
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return
+	}
+
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.db.DeleteStrategyTable(r.Context(), strategy.ID, tableID, version, revisionAuthor(r)); err != nil {
+		switch {
+		case errors.Is(err, database.ErrVersionConflict):
+			writeVersionConflict(w, r)
+		case errors.Is(err, sql.ErrNoRows):
+			NewError(r.Context(), w, http.StatusNotFound, "No table found with this ID", r.URL.Path)
+		default:
+			NewInternalError(r.Context(), w, "deleting strategy table", err, r.URL.Path)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) AddStrategyItemHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.editableStrategy(w, r)
+	if !ok {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
+	var strategyItem models.StrategyItem
+	statusCode, err := DecodeJSON(r, &strategyItem)
+	if err != nil {
+		NewError(r.Context(), w, statusCode, err.Error(), r.URL.Path)
+		return
+	}
 
-	if err = json.NewEncoder(w).Encode(storedTable); err != nil {
-		slog.Error("failed to encode success response", "error", err)
+	strategyItem.SID = 0
+
+	errs, err := s.validateStrategyItem(r.Context(), strategy.ID, strategyItem)
+	if err != nil {
+		NewInternalError(r.Context(), w, "validating strategy item", err, r.URL.Path)
+		return
+	}
+
+	if len(errs) > 0 {
+		NewFieldErrors(r.Context(), w, http.StatusUnprocessableEntity, "Invalid strategy item.", errs, r.URL.Path)
+		return
+	}
+
+	storedItem, err := s.db.StoreStrategyItem(r.Context(), strategy.ID, strategyItem, revisionAuthor(r))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			NewError(r.Context(), w, http.StatusNotFound, "No table found with this ID", r.URL.Path)
+			return
+		}
+
+		NewInternalError(r.Context(), w, "storing strategy item", err, r.URL.Path)
+		return
+	}
+
+	setETag(w, storedItem.Version)
+	WriteJSON(r.Context(), w, http.StatusCreated, storedItem)
+}
+
+func (s *Server) ListStrategyItemHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.viewableStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	tableID, err := PathID(r, "table_id")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return
+	}
+
+	items, err := s.db.RetrieveStrategyItems(r.Context(), strategy.ID, tableID)
+	if err != nil {
+		NewInternalError(r.Context(), w, "retrieving strategy items", err, r.URL.Path)
+		return
 	}
+
+	WriteJSON(r.Context(), w, http.StatusOK, items)
+}
+
+func (s *Server) UpdateStrategyItemHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.editableStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	tableID, err := PathID(r, "table_id")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return
+	}
+
+	itemID, err := PathID(r, "item_id")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return
+	}
+
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	var strategyItem models.StrategyItem
+	statusCode, err := DecodeJSON(r, &strategyItem)
+	if err != nil {
+		NewError(r.Context(), w, statusCode, err.Error(), r.URL.Path)
+		return
+	}
+
+	strategyItem.SID = itemID
+	strategyItem.StrategyID = strategy.ID
+	strategyItem.TableID = tableID
+	strategyItem.Version = version
+
+	errs, err := s.validateStrategyItem(r.Context(), strategy.ID, strategyItem)
+	if err != nil {
+		NewInternalError(r.Context(), w, "validating strategy item", err, r.URL.Path)
+		return
+	}
+
+	if len(errs) > 0 {
+		NewFieldErrors(r.Context(), w, http.StatusUnprocessableEntity, "Invalid strategy item.", errs, r.URL.Path)
+		return
+	}
+
+	updated, err := s.db.UpdateStrategyItem(r.Context(), strategyItem, revisionAuthor(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrVersionConflict):
+			writeVersionConflict(w, r)
+		case errors.Is(err, sql.ErrNoRows):
+			NewError(r.Context(), w, http.StatusNotFound, "No strategy item found with this ID", r.URL.Path)
+		default:
+			NewInternalError(r.Context(), w, "updating strategy item", err, r.URL.Path)
+		}
+		return
+	}
+
+	setETag(w, updated.Version)
+	WriteJSON(r.Context(), w, http.StatusOK, updated)
+}
+
+func (s *Server) DeleteStrategyItemHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.editableStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	tableID, err := PathID(r, "table_id")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return
+	}
+
+	itemID, err := PathID(r, "item_id")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return
+	}
+
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.db.DeleteStrategyItem(r.Context(), strategy.ID, tableID, itemID, version, revisionAuthor(r)); err != nil {
+		switch {
+		case errors.Is(err, database.ErrVersionConflict):
+			writeVersionConflict(w, r)
+		case errors.Is(err, sql.ErrNoRows):
+			NewError(r.Context(), w, http.StatusNotFound, "No strategy item found with this ID", r.URL.Path)
+		default:
+			NewInternalError(r.Context(), w, "deleting strategy item", err, r.URL.Path)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
 }
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/Vyary/api/internal/database"
+	"github.com/Vyary/api/internal/models"
+)
+
+type TagsDTO struct {
+	Tags []models.Tag `json:"tags"`
+}
+
+func (s *Server) SetStrategyTagsHandler(w http.ResponseWriter, r *http.Request) {
+	strategy, ok := s.editableStrategy(w, r)
+	if !ok {
+		return
+	}
+
+	// tags belong to the strategy itself, so they are guarded by its ETag
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	var req TagsDTO
+	statusCode, err := DecodeJSON(r, &req)
+	if err != nil {
+		NewError(r.Context(), w, statusCode, err.Error(), r.URL.Path)
+		return
+	}
+
+	tags := normalizeTags(req.Tags)
+
+	if errs := validateTags(tags); len(errs) > 0 {
+		NewFieldErrors(r.Context(), w, http.StatusUnprocessableEntity, "Invalid tags.", errs, r.URL.Path)
+		return
+	}
+
+	updated, err := s.db.SetStrategyTags(r.Context(), strategy.ID, tags, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrVersionConflict):
+			writeVersionConflict(w, r)
+		case errors.Is(err, sql.ErrNoRows):
+			NewError(r.Context(), w, http.StatusNotFound, "No strategy found with this ID", r.URL.Path)
+		default:
+			NewInternalError(r.Context(), w, "storing strategy tags", err, r.URL.Path)
+		}
+		return
+	}
+
+	setETag(w, updated)
+	WriteJSON(r.Context(), w, http.StatusOK, TagsDTO{Tags: tags})
+}
+
+// TagSuggestionsHandler autocompletes tag names from the tags of public
+// strategies, optionally within a single category.
+func (s *Server) TagSuggestionsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, _ := parsePagination(r)
+
+	category := query.Get("category")
+	if category != "" && !slices.Contains(models.TagCategories, category) {
+		NewError(r.Context(), w, http.StatusBadRequest, "Invalid tag category.", r.URL.Path)
+		return
+	}
+
+	suggestions, err := s.db.ListTagSuggestions(r.Context(), normalizeTagName(query.Get("prefix")), category, limit)
+	if err != nil {
+		NewInternalError(r.Context(), w, "listing tag suggestions", err, r.URL.Path)
+		return
+	}
+
+	WriteJSON(r.Context(), w, http.StatusOK, suggestions)
+}
@@ -0,0 +1,214 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+func (s *Server) ListTemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	templates, err := s.db.ListTemplates(r.Context())
+	if err != nil {
+		NewInternalError(r.Context(), w, "listing templates", err, r.URL.Path)
+		return
+	}
+
+	WriteJSON(r.Context(), w, http.StatusOK, templates)
+}
+
+func (s *Server) GetTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	templateID, err := PathID(r, "template_id")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return
+	}
+
+	template, err := s.db.RetrieveTemplate(r.Context(), templateID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			NewError(r.Context(), w, http.StatusNotFound, "No template found with this ID", r.URL.Path)
+			return
+		}
+
+		NewInternalError(r.Context(), w, "retrieving template", err, r.URL.Path)
+		return
+	}
+
+	WriteJSON(r.Context(), w, http.StatusOK, template)
+}
+
+func (s *Server) CreateTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := s.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	var template models.StrategyTemplate
+	statusCode, err := DecodeJSON(r, &template)
+	if err != nil {
+		NewError(r.Context(), w, statusCode, err.Error(), r.URL.Path)
+		return
+	}
+
+	if ok := s.checkTemplate(w, r, &template); !ok {
+		return
+	}
+
+	template.CreatedBy = claims.UserName
+
+	stored, err := s.db.StoreTemplate(r.Context(), template)
+	if err != nil {
+		NewInternalError(r.Context(), w, "storing template", err, r.URL.Path)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/templates/%d", stored.ID))
+
+	WriteJSON(r.Context(), w, http.StatusCreated, stored)
+}
+
+func (s *Server) UpdateTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.requireAdmin(w, r); !ok {
+		return
+	}
+
+	templateID, err := PathID(r, "template_id")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return
+	}
+
+	var template models.StrategyTemplate
+	statusCode, err := DecodeJSON(r, &template)
+	if err != nil {
+		NewError(r.Context(), w, statusCode, err.Error(), r.URL.Path)
+		return
+	}
+
+	if ok := s.checkTemplate(w, r, &template); !ok {
+		return
+	}
+
+	template.ID = templateID
+
+	updated, err := s.db.UpdateTemplate(r.Context(), template)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			NewError(r.Context(), w, http.StatusNotFound, "No template found with this ID", r.URL.Path)
+			return
+		}
+
+		NewInternalError(r.Context(), w, "updating template", err, r.URL.Path)
+		return
+	}
+
+	WriteJSON(r.Context(), w, http.StatusOK, updated)
+}
+
+func (s *Server) DeleteTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.requireAdmin(w, r); !ok {
+		return
+	}
+
+	templateID, err := PathID(r, "template_id")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return
+	}
+
+	if err := s.db.DeleteTemplate(r.Context(), templateID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			NewError(r.Context(), w, http.StatusNotFound, "No template found with this ID", r.URL.Path)
+			return
+		}
+
+		NewInternalError(r.Context(), w, "deleting template", err, r.URL.Path)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateStrategyFromTemplateHandler copies a template into a new private
+// strategy of the caller.
+func (s *Server) CreateStrategyFromTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := GetUser(r)
+	if err != nil {
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
+		return
+	}
+
+	templateID, err := PathID(r, "template_id")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return
+	}
+
+	created, err := s.db.InstantiateTemplate(r.Context(), templateID, *user)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			NewError(r.Context(), w, http.StatusNotFound, "No template found with this ID", r.URL.Path)
+			return
+		}
+
+		NewInternalError(r.Context(), w, "creating strategy from template", err, r.URL.Path)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/strategies/%d", created.ID))
+	setETag(w, created.Version)
+
+	WriteJSON(r.Context(), w, http.StatusCreated, created.DTO())
+}
+
+// checkTemplate runs every template rule, including that its placeholder
+// items exist. On failure the error response has already been written.
+func (s *Server) checkTemplate(w http.ResponseWriter, r *http.Request, template *models.StrategyTemplate) bool {
+	errs := validateTemplate(template)
+
+	if err := s.validateTemplateItems(r.Context(), template, errs); err != nil {
+		NewInternalError(r.Context(), w, "validating template items", err, r.URL.Path)
+		return false
+	}
+
+	if len(errs) > 0 {
+		NewFieldErrors(r.Context(), w, http.StatusUnprocessableEntity, "Invalid template.", errs, r.URL.Path)
+		return false
+	}
+
+	return true
+}
+
+func (s *Server) validateTemplateItems(ctx context.Context, template *models.StrategyTemplate, errs Errors) error {
+	ids := make([]string, 0)
+	for _, t := range template.Tables {
+		for _, item := range t.Items {
+			if item.ItemID != "" {
+				ids = append(ids, item.ItemID)
+			}
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	existing, err := s.db.ExistingItemIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for ti, t := range template.Tables {
+		for ii, item := range t.Items {
+			if item.ItemID != "" && !existing[item.ItemID] {
+				errs[fmt.Sprintf("tables[%d].items[%d].item_id", ti, ii)] = "does not exist"
+			}
+		}
+	}
+
+	return nil
+}
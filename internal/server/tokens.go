@@ -1,6 +1,8 @@
 package server
 
 import (
+	"log/slog"
+	"os"
 	"time"
 
 	"github.com/Vyary/api/internal/models"
@@ -8,25 +10,65 @@ import (
 	"github.com/google/uuid"
 )
 
+const defaultJWTExpiration = 15 * time.Minute
+
 var (
-	jwtExpiration        = 24 * time.Hour * 7
+	jwtExpiration        = accessTokenExpiration()
 	jwtRefreshExpiration = 24 * time.Hour * 30
 )
 
-func (s *Server) GenTokenPair(user models.UserProfile) (*models.TokenPair, error) {
+// accessTokenExpiration reads the access token lifetime from JWT_EXPIRATION.
+// Access tokens are checked against the denylist rather than the database,
+// so keeping them short-lived bounds the damage of a stale cache.
+func accessTokenExpiration() time.Duration {
+	if v := os.Getenv("JWT_EXPIRATION"); v != "" {
+		expiration, err := time.ParseDuration(v)
+		if err == nil && expiration > 0 {
+			return expiration
+		}
+
+		slog.Warn("invalid JWT_EXPIRATION, using default", "value", v)
+	}
+
+	return defaultJWTExpiration
+}
+
+// Audiences keep access and refresh tokens from being used in place of one
+// another.
+const (
+	accessAudience  = "exile-profit:access"
+	refreshAudience = "exile-profit:refresh"
+)
+
+// audiencesIntroduced bounds when tokens started carrying an audience, with
+// room for the release to roll out. Refresh tokens issued before it are
+// still honoured until they expire, so the fallback can be removed one
+// refresh lifetime later.
+var audiencesIntroduced = time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC)
+
+// GenTokenPair issues an access and a refresh token with distinct ids. An
+// empty familyID starts a new token family, as on login; refreshing passes
+// the family of the rotated token. The client is recorded with the refresh
+// token to describe the session.
+func (s *Server) GenTokenPair(user models.UserProfile, familyID string, client models.SessionClient) (*models.TokenPair, error) {
 	now := time.Now()
-	tokenID := uuid.New().String()
+
+	if familyID == "" {
+		familyID = uuid.New().String()
+	}
 
 	jwtClaims := models.JWTClaims{
 		UserID:   user.ID,
 		UserName: user.Name,
-		TokenID:  tokenID,
+		TokenID:  uuid.New().String(),
+		FamilyID: familyID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(now.Add(jwtExpiration)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "exile-profit",
 			Subject:   user.ID,
+			Audience:  jwt.ClaimStrings{accessAudience},
 		},
 	}
 
@@ -37,16 +79,20 @@ func (s *Server) GenTokenPair(user models.UserProfile) (*models.TokenPair, error
 		return nil, err
 	}
 
+	refreshTokenID := uuid.New().String()
+
 	jwtRefreshClaim := models.JWTClaims{
 		UserID:   user.ID,
 		UserName: user.Name,
-		TokenID:  tokenID,
+		TokenID:  refreshTokenID,
+		FamilyID: familyID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(now.Add(jwtRefreshExpiration)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "exile-profit",
 			Subject:   user.ID,
+			Audience:  jwt.ClaimStrings{refreshAudience},
 		},
 	}
 
@@ -57,7 +103,7 @@ func (s *Server) GenTokenPair(user models.UserProfile) (*models.TokenPair, error
 		return nil, err
 	}
 
-	if err := s.db.StoreRefreshToken(user.ID, tokenID, jwtRefreshExpiration); err != nil {
+	if err := s.db.StoreRefreshToken(user.ID, refreshTokenID, familyID, jwtRefreshExpiration, client); err != nil {
 		return nil, err
 	}
 
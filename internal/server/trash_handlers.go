@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+const defaultTrashRetention = 30 * 24 * time.Hour
+
+type TrashDTO struct {
+	Strategies []models.StrategyDTO `json:"strategies"`
+	// Retention is how long, in seconds, strategies stay in the trash
+	// before they are permanently deleted.
+	Retention int64 `json:"retention"`
+	Limit     int   `json:"limit"`
+	Offset    int   `json:"offset"`
+	Total     int   `json:"total"`
+}
+
+// trashRetention reads TRASH_RETENTION, defaulting to 30 days.
+func trashRetention() time.Duration {
+	if v := os.Getenv("TRASH_RETENTION"); v != "" {
+		retention, err := time.ParseDuration(v)
+		if err == nil && retention > 0 {
+			return retention
+		}
+
+		slog.Warn("invalid TRASH_RETENTION, using default", "value", v)
+	}
+
+	return defaultTrashRetention
+}
+
+// runTrashPurge permanently deletes strategies that have been in the trash
+// longer than the retention, checking every interval until ctx is cancelled.
+// Expired token revocations are purged along the way.
+func (s *Server) runTrashPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		before := time.Now().Add(-s.trashRetention).Unix()
+
+		n, err := s.db.PurgeDeletedStrategies(ctx, before)
+		if err != nil {
+			CaptureError(ctx, "purging deleted strategies", err)
+		}
+		if n > 0 {
+			slog.Info("purged deleted strategies", "count", n)
+		}
+
+		if err := s.db.PurgeRevokedAccessTokens(ctx); err != nil {
+			CaptureError(ctx, "purging revoked tokens", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) ListTrashHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := GetUser(r)
+	if err != nil {
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
+		return
+	}
+
+	limit, offset := parsePagination(r)
+
+	strategies, total, err := s.db.ListTrash(r.Context(), user.ID, limit, offset)
+	if err != nil {
+		NewInternalError(r.Context(), w, "listing trash", err, r.URL.Path)
+		return
+	}
+
+	result := TrashDTO{
+		Strategies: strategies,
+		Retention:  int64(s.trashRetention.Seconds()),
+		Limit:      limit,
+		Offset:     offset,
+		Total:      total,
+	}
+
+	WriteJSON(r.Context(), w, http.StatusOK, result)
+}
+
+func (s *Server) RestoreStrategyHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := GetUser(r)
+	if err != nil {
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
+		return
+	}
+
+	strategyID, err := PathID(r, "strategy_id")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return
+	}
+
+	restored, err := s.db.RestoreStrategy(r.Context(), user.ID, strategyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			NewError(r.Context(), w, http.StatusNotFound, "No deleted strategy found with this ID", r.URL.Path)
+			return
+		}
+
+		NewInternalError(r.Context(), w, "restoring strategy", err, r.URL.Path)
+		return
+	}
+
+	setETag(w, restored.Version)
+	WriteJSON(r.Context(), w, http.StatusOK, restored.DTO())
+}
+
+func (s *Server) PurgeStrategyHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := GetUser(r)
+	if err != nil {
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
+		return
+	}
+
+	strategyID, err := PathID(r, "strategy_id")
+	if err != nil {
+		NewError(r.Context(), w, http.StatusBadRequest, err.Error(), r.URL.Path)
+		return
+	}
+
+	if err := s.db.PurgeStrategy(r.Context(), user.ID, strategyID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			NewError(r.Context(), w, http.StatusNotFound, "No deleted strategy found with this ID", r.URL.Path)
+			return
+		}
+
+		NewInternalError(r.Context(), w, "purging strategy", err, r.URL.Path)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -1,10 +1,13 @@
 package server
 
 import (
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
 
 	"github.com/Vyary/api/internal/models"
 	"github.com/golang-jwt/jwt/v5"
@@ -33,8 +36,52 @@ func GetUser(r *http.Request) (*models.UserProfile, error) {
 	return &models.UserProfile{ID: claims.UserID, Name: claims.UserName}, nil
 }
 
+// GetClaims reads the claims of the access token cookie.
 func GetClaims(r *http.Request) (*models.JWTClaims, error) {
-	tokenCookie, err := r.Cookie("jwt_token")
+	claims, err := readTokenCookie(r, "jwt_token", accessAudience)
+	if err != nil {
+		return nil, err
+	}
+
+	if revokedTokens.contains(claims.TokenID, claims.FamilyID) {
+		return nil, errors.New("JWT token has been revoked")
+	}
+
+	return claims, nil
+}
+
+// GetRefreshClaims reads the claims of the refresh token cookie. Refresh
+// tokens issued before audiences were introduced carry none; they are
+// accepted until they expire so their sessions survive, and rotating them
+// re-issues them with the refresh audience.
+func GetRefreshClaims(r *http.Request) (*models.JWTClaims, error) {
+	claims, err := readTokenCookie(r, "jwt_refresh", refreshAudience)
+	if !errors.Is(err, jwt.ErrTokenRequiredClaimMissing) {
+		return claims, err
+	}
+
+	legacy, legacyErr := readTokenCookie(r, "jwt_refresh", "")
+	if legacyErr != nil || !isLegacyRefreshToken(legacy) {
+		return nil, err
+	}
+
+	return legacy, nil
+}
+
+// isLegacyRefreshToken reports whether a token without an audience was
+// issued before audiences were introduced. Later tokens always carry one.
+func isLegacyRefreshToken(claims *models.JWTClaims) bool {
+	if len(claims.Audience) > 0 || claims.IssuedAt == nil {
+		return false
+	}
+
+	return claims.IssuedAt.Before(audiencesIntroduced)
+}
+
+// readTokenCookie parses the token of a cookie. An empty audience skips the
+// audience check.
+func readTokenCookie(r *http.Request, name string, audience string) (*models.JWTClaims, error) {
+	tokenCookie, err := r.Cookie(name)
 	if err != nil {
 		if errors.Is(err, http.ErrNoCookie) {
 			return nil, errors.New("JWT cookie not found")
@@ -46,13 +93,18 @@ func GetClaims(r *http.Request) (*models.JWTClaims, error) {
 		return nil, errors.New("JWT cookie is empty")
 	}
 
+	var options []jwt.ParserOption
+	if audience != "" {
+		options = append(options, jwt.WithAudience(audience))
+	}
+
 	token, err := jwt.ParseWithClaims(tokenCookie.Value, &models.JWTClaims{}, func(t *jwt.Token) (any, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
 		}
 
 		return []byte(jwtSecret), nil
-	})
+	}, options...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse JWT token: %w", err)
 	}
@@ -80,3 +132,73 @@ func DecodeJSON(r *http.Request, data any) (statusCode int, err error) {
 
 	return http.StatusOK, nil
 }
+
+func PathID(r *http.Request, name string) (int, error) {
+	id, err := strconv.Atoi(r.PathValue(name))
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid %s", name)
+	}
+
+	return id, nil
+}
+
+// requireAdmin authenticates the request and checks the caller's role.
+// On failure the error response has already been written.
+func (s *Server) requireAdmin(w http.ResponseWriter, r *http.Request) (*models.JWTClaims, bool) {
+	claims, err := GetClaims(r)
+	if err != nil {
+		NewError(r.Context(), w, http.StatusUnauthorized, err.Error(), r.URL.Path)
+		return nil, false
+	}
+
+	role, err := s.db.RetrieveUserRole(r.Context(), claims.UserID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		NewInternalError(r.Context(), w, "retrieving user role", err, r.URL.Path)
+		return nil, false
+	}
+
+	if role != models.RoleAdmin {
+		NewError(r.Context(), w, http.StatusForbidden, "Admin role required.", r.URL.Path)
+		return nil, false
+	}
+
+	return claims, true
+}
+
+// parseLeague reads the league query parameter, defaulting to softcore.
+func parseLeague(r *http.Request) string {
+	if r.URL.Query().Get("league") == "chc" {
+		return "chc"
+	}
+
+	return "csc"
+}
+
+// strategyLeague reads the league query parameter, defaulting to the league
+// selected on the strategy.
+func strategyLeague(r *http.Request, strategy *models.Strategy) string {
+	if league := r.URL.Query().Get("league"); slices.Contains(leagues, league) {
+		return league
+	}
+
+	if slices.Contains(leagues, strategy.League) {
+		return strategy.League
+	}
+
+	return models.LeagueSoftcore
+}
+
+// parsePagination reads limit and offset, capping limit at 100.
+func parsePagination(r *http.Request) (limit int, offset int) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+
+	offset, err = strconv.Atoi(r.URL.Query().Get("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	return min(limit, 100), offset
+}
@@ -0,0 +1,361 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Vyary/api/internal/atlas"
+	"github.com/Vyary/api/internal/models"
+)
+
+const (
+	maxStrategyNameLength        = 100
+	maxStrategyDescriptionLength = 5000
+	maxStrategyAtlasLength       = 200
+	maxTableTitleLength          = 100
+	maxItemAmount                = 1_000_000
+	maxTableScale                = 10
+	maxStrategyTags              = 10
+	maxTagLength                 = 32
+
+	// maxGroupChance leaves room for float32 drop chances that add up to
+	// slightly more than 1.
+	maxGroupChance = 1 + 1e-6
+)
+
+func validateLength(errs Errors, field string, value string, limit int) {
+	if utf8.RuneCountInString(value) > limit {
+		errs[field] = fmt.Sprintf("must be at most %d characters", limit)
+	}
+}
+
+// validateStrategy checks a strategy, defaults its league and decodes its
+// atlas tree in place so the stored tree carries the nodes clients render.
+func validateStrategy(st *models.Strategy) Errors {
+	errs := Errors{}
+
+	if strings.TrimSpace(st.Name) == "" {
+		errs["name"] = "must not be empty"
+	}
+	validateLength(errs, "name", st.Name, maxStrategyNameLength)
+	validateLength(errs, "description", st.Description, maxStrategyDescriptionLength)
+	validateLength(errs, "atlas", st.Atlas, maxStrategyAtlasLength)
+
+	if st.Duration < 0 || st.Duration > maxRunDuration {
+		errs["duration"] = fmt.Sprintf("must be between 0 and %d seconds", maxRunDuration)
+	}
+
+	if st.League == "" {
+		st.League = models.LeagueSoftcore
+	}
+	if !slices.Contains(leagues, st.League) {
+		errs["league"] = "must be one of " + strings.Join(leagues, ", ")
+	}
+
+	if st.AtlasTree != nil {
+		errs.Merge("atlas_tree.", decodeAtlasTree(st.AtlasTree))
+	}
+
+	return errs
+}
+
+// decodeAtlasTree replaces the decoded fields of the tree with those read
+// from its code and normalises the mechanics.
+func decodeAtlasTree(tree *models.AtlasTree) Errors {
+	errs := Errors{}
+
+	decoded, err := atlas.Decode(tree.Code)
+	if err != nil {
+		errs["code"] = err.Error()
+	}
+
+	mechanics := make([]string, 0, len(tree.Mechanics))
+	for i, m := range tree.Mechanics {
+		m = strings.ToLower(strings.TrimSpace(m))
+
+		if !slices.Contains(models.AtlasMechanics, m) {
+			errs[fmt.Sprintf("mechanics[%d]", i)] = "must be one of " + strings.Join(models.AtlasMechanics, ", ")
+			continue
+		}
+
+		if !slices.Contains(mechanics, m) {
+			mechanics = append(mechanics, m)
+		}
+	}
+
+	decoded.Mechanics = mechanics
+	*tree = decoded
+
+	return errs
+}
+
+// normalizeTags lowercases tag names, collapses their whitespace and drops
+// duplicates.
+func normalizeTags(tags []models.Tag) []models.Tag {
+	normalized := make([]models.Tag, 0, len(tags))
+
+	for _, t := range tags {
+		t.Name = normalizeTagName(t.Name)
+
+		if !slices.ContainsFunc(normalized, func(n models.Tag) bool { return n.Name == t.Name }) {
+			normalized = append(normalized, t)
+		}
+	}
+
+	return normalized
+}
+
+func normalizeTagName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+func validateTags(tags []models.Tag) Errors {
+	errs := Errors{}
+
+	if len(tags) > maxStrategyTags {
+		errs["tags"] = fmt.Sprintf("must have at most %d tags", maxStrategyTags)
+	}
+
+	for i, t := range tags {
+		field := fmt.Sprintf("tags[%d].", i)
+
+		if t.Name == "" {
+			errs[field+"name"] = "must not be empty"
+		}
+		validateLength(errs, field+"name", t.Name, maxTagLength)
+
+		switch {
+		case !slices.Contains(models.TagCategories, t.Category):
+			errs[field+"category"] = "must be one of " + strings.Join(models.TagCategories, ", ")
+		case t.Category == models.TagCategoryMechanic && !slices.Contains(models.AtlasMechanics, t.Name):
+			errs[field+"name"] = "must be a known mechanic"
+		}
+	}
+
+	return errs
+}
+
+// normalizeTable defaults an omitted scale to 1.
+func normalizeTable(t *models.StrategyTable) {
+	if t.Scale == 0 {
+		t.Scale = 1
+	}
+}
+
+func validateTable(t models.StrategyTable) Errors {
+	errs := Errors{}
+
+	validateLength(errs, "title", t.Title, maxTableTitleLength)
+
+	kind, ok := models.LookupTableKind(t.Type)
+	if !ok {
+		types := make([]string, len(models.TableKinds))
+		for i, k := range models.TableKinds {
+			types[i] = k.Type
+		}
+
+		errs["type"] = "must be one of " + strings.Join(types, ", ")
+		return errs
+	}
+
+	switch {
+	case kind.Scales && (t.Scale <= 0 || t.Scale > maxTableScale):
+		errs["scale"] = fmt.Sprintf("must be greater than 0 and at most %d", maxTableScale)
+	case !kind.Scales && t.Scale != 1:
+		errs["scale"] = "only applies to modifiers tables"
+	}
+
+	return errs
+}
+
+// validateItem checks the fields of an item that do not depend on the rest
+// of the strategy.
+func validateItem(item models.StrategyItem) Errors {
+	errs := Errors{}
+
+	if item.ItemID == "" {
+		errs["item_id"] = "must not be empty"
+	}
+
+	if item.Amount <= 0 || item.Amount > maxItemAmount {
+		errs["amount"] = fmt.Sprintf("must be between 1 and %d", maxItemAmount)
+	}
+
+	if item.Role != models.ItemRoleInput && item.Role != models.ItemRoleOutput {
+		errs["role"] = fmt.Sprintf("must be %s or %s", models.ItemRoleInput, models.ItemRoleOutput)
+	}
+
+	if item.DropChance < 0 || item.DropChance > 1 {
+		errs["drop_chance"] = "must be between 0 and 1"
+	}
+
+	if item.PriceOverride != nil && *item.PriceOverride < 0 {
+		errs["price_override"] = "must not be negative"
+	}
+
+	return errs
+}
+
+// validateItemKind checks the item against the semantics of its table.
+func validateItemKind(errs Errors, prefix string, item models.StrategyItem, kind models.TableKind) {
+	if _, ok := errs[prefix+"role"]; !ok && item.Role != kind.Role {
+		errs[prefix+"role"] = fmt.Sprintf("must be %s in %s tables", kind.Role, kind.Type)
+	}
+
+	if item.Pair != 0 && !kind.Pairs {
+		errs[prefix+"pair"] = fmt.Sprintf("is not allowed in %s tables", kind.Type)
+	}
+}
+
+// validatePair checks that a paired item refers to another item of the same
+// table and that at most one item of its either-or group can drop. A zero
+// pair means the item is not paired, though others may be paired with it.
+func validatePair(errs Errors, field string, item models.StrategyItem, siblings []models.StrategyItem) {
+	if item.Pair != 0 {
+		if item.Pair == item.SID {
+			errs[field] = "must not reference the item itself"
+			return
+		}
+
+		if !slices.ContainsFunc(siblings, func(s models.StrategyItem) bool { return s.SID == item.Pair }) {
+			errs[field] = "must reference an item in the same table"
+			return
+		}
+	}
+
+	if pairGroupChance(item, siblings) > maxGroupChance {
+		errs[field] = "drop chances of the either-or group must add up to at most 1"
+	}
+}
+
+// pairGroupChance returns the combined drop chance of the either-or group
+// the item forms with its siblings. Siblings with the item's id are
+// replaced by the item.
+func pairGroupChance(item models.StrategyItem, siblings []models.StrategyItem) float64 {
+	items := make([]models.StrategyItem, 0, len(siblings)+1)
+	for _, sibling := range siblings {
+		if item.SID == 0 || sibling.SID != item.SID {
+			items = append(items, sibling)
+		}
+	}
+	items = append(items, item)
+
+	// the item is last; walk the pair links in both directions from it
+	inGroup := make([]bool, len(items))
+	inGroup[len(items)-1] = true
+	queue := []int{len(items) - 1}
+
+	for len(queue) > 0 {
+		current := items[queue[0]]
+		queue = queue[1:]
+
+		for i, other := range items {
+			if inGroup[i] {
+				continue
+			}
+
+			linked := (current.Pair != 0 && current.Pair == other.SID) || (other.Pair != 0 && other.Pair == current.SID && current.SID != 0)
+			if linked {
+				inGroup[i] = true
+				queue = append(queue, i)
+			}
+		}
+	}
+
+	var total float64
+	for i, in := range inGroup {
+		if in {
+			total += float64(items[i].DropChance)
+		}
+	}
+
+	return total
+}
+
+// validateStrategyItem runs every item rule, including those that need the
+// database: the pair must be in the same table, its group must not drop more
+// than once and the item must exist.
+func (s *Server) validateStrategyItem(ctx context.Context, strategyID int, item models.StrategyItem) (Errors, error) {
+	errs := validateItem(item)
+
+	tables, err := s.db.RetrieveStrategyTables(ctx, strategyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// a missing table is reported as not found by the store
+	for _, t := range tables {
+		if t.ID != item.TableID {
+			continue
+		}
+
+		if kind, ok := models.LookupTableKind(t.Type); ok {
+			validateItemKind(errs, "", item, kind)
+		}
+	}
+
+	if _, ok := errs["pair"]; !ok {
+		siblings, err := s.db.RetrieveStrategyItems(ctx, strategyID, item.TableID)
+		if err != nil {
+			return nil, err
+		}
+
+		validatePair(errs, "pair", item, siblings)
+	}
+
+	if _, ok := errs["item_id"]; !ok {
+		existing, err := s.db.ExistingItemIDs(ctx, []string{item.ItemID})
+		if err != nil {
+			return nil, err
+		}
+
+		if !existing[item.ItemID] {
+			errs["item_id"] = "does not exist"
+		}
+	}
+
+	return errs, nil
+}
+
+// validateTemplate checks a template the way a strategy with the same tables
+// would be checked, using item ids as the keys Pair refers to. Whether the
+// items exist is checked separately.
+func validateTemplate(t *models.StrategyTemplate) Errors {
+	errs := validateStrategy(&models.Strategy{Name: t.Name, Description: t.Description, Atlas: t.Atlas, Duration: t.Duration})
+	seen := make(map[int]bool)
+
+	for ti := range t.Tables {
+		table := &t.Tables[ti]
+		normalizeTable(&table.StrategyTable)
+
+		errs.Merge(fmt.Sprintf("tables[%d].", ti), validateTable(table.StrategyTable))
+		kind, known := models.LookupTableKind(table.Type)
+
+		for ii, item := range table.Items {
+			field := fmt.Sprintf("tables[%d].items[%d]", ti, ii)
+
+			switch {
+			case item.SID <= 0:
+				errs[field+".id"] = "must be a positive integer"
+			case seen[item.SID]:
+				errs[field+".id"] = "must be unique within the template"
+			}
+			seen[item.SID] = true
+
+			errs.Merge(field+".", validateItem(item))
+
+			if known {
+				validateItemKind(errs, field+".", item, kind)
+			}
+
+			if _, ok := errs[field+".pair"]; !ok {
+				validatePair(errs, field+".pair", item, table.Items)
+			}
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,326 @@
+package server
+
+import (
+	"encoding/base64"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Vyary/api/internal/models"
+)
+
+// init exits without the secrets, package variables are set up before it runs.
+var _ = func() bool {
+	for _, key := range []string{"CLIENT_SECRET", "JWT_SECRET"} {
+		if os.Getenv(key) == "" {
+			os.Setenv(key, "test")
+		}
+	}
+	return true
+}()
+
+// assertFields checks that errs reports exactly the given fields.
+func assertFields(t *testing.T, errs Errors, fields ...string) {
+	t.Helper()
+
+	got := slices.Sorted(maps.Keys(errs))
+	slices.Sort(fields)
+
+	if !slices.Equal(got, fields) {
+		t.Errorf("errors on %v, want %v (%v)", got, fields, errs)
+	}
+}
+
+func TestValidateStrategy(t *testing.T) {
+	code := base64.RawURLEncoding.EncodeToString([]byte{0, 0, 0, 5, 0, 0, 0, 0, 42})
+
+	tests := []struct {
+		name     string
+		strategy models.Strategy
+		fields   []string
+	}{
+		{"valid", models.Strategy{Name: "Harvest", Duration: 60}, nil},
+		{"blank name", models.Strategy{Name: "  "}, []string{"name"}},
+		{"long name", models.Strategy{Name: strings.Repeat("é", maxStrategyNameLength+1)}, []string{"name"}},
+		{"long description", models.Strategy{Name: "a", Description: strings.Repeat("a", maxStrategyDescriptionLength+1)}, []string{"description"}},
+		{"long atlas", models.Strategy{Name: "a", Atlas: strings.Repeat("a", maxStrategyAtlasLength+1)}, []string{"atlas"}},
+		{"negative duration", models.Strategy{Name: "a", Duration: -1}, []string{"duration"}},
+		{"duration too long", models.Strategy{Name: "a", Duration: maxRunDuration + 1}, []string{"duration"}},
+		{"unknown league", models.Strategy{Name: "a", League: "std"}, []string{"league"}},
+		{"valid atlas tree", models.Strategy{Name: "a", AtlasTree: &models.AtlasTree{Code: code, Mechanics: []string{" Harvest "}}}, nil},
+		{"invalid atlas tree", models.Strategy{Name: "a", AtlasTree: &models.AtlasTree{Code: "!", Mechanics: []string{"harvest", "unknown"}}}, []string{"atlas_tree.code", "atlas_tree.mechanics[1]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFields(t, validateStrategy(&tt.strategy), tt.fields...)
+		})
+	}
+}
+
+func TestValidateStrategyNormalizes(t *testing.T) {
+	code := base64.RawURLEncoding.EncodeToString([]byte{0, 0, 0, 5, 0, 0, 0, 0, 42})
+	st := models.Strategy{
+		Name:      "Harvest",
+		AtlasTree: &models.AtlasTree{Code: "https://example.com/tree/" + code, Nodes: []int{1}, Mechanics: []string{"Harvest", "harvest ", "ritual"}},
+	}
+
+	assertFields(t, validateStrategy(&st))
+
+	if st.League != models.LeagueSoftcore {
+		t.Errorf("League = %q, want %q", st.League, models.LeagueSoftcore)
+	}
+	if st.AtlasTree.Code != code || !slices.Equal(st.AtlasTree.Nodes, []int{42}) {
+		t.Errorf("AtlasTree = %+v, want the decoded code %q with node 42", st.AtlasTree, code)
+	}
+	if !slices.Equal(st.AtlasTree.Mechanics, []string{"harvest", "ritual"}) {
+		t.Errorf("Mechanics = %v, want [harvest ritual]", st.AtlasTree.Mechanics)
+	}
+}
+
+func TestValidateTags(t *testing.T) {
+	many := make([]models.Tag, maxStrategyTags+1)
+	for i := range many {
+		many[i] = models.Tag{Name: "t", Category: models.TagCategoryMap}
+	}
+
+	tests := []struct {
+		name   string
+		tags   []models.Tag
+		fields []string
+	}{
+		{"none", nil, nil},
+		{"valid", []models.Tag{{Name: "harvest", Category: models.TagCategoryMechanic}, {Name: "strand", Category: models.TagCategoryMap}}, nil},
+		{"too many", many, []string{"tags"}},
+		{"empty name", []models.Tag{{Category: models.TagCategoryMap}}, []string{"tags[0].name"}},
+		{"long name", []models.Tag{{Name: strings.Repeat("a", maxTagLength+1), Category: models.TagCategoryMap}}, []string{"tags[0].name"}},
+		{"unknown category", []models.Tag{{Name: "a", Category: "colour"}}, []string{"tags[0].category"}},
+		{"unknown mechanic", []models.Tag{{Name: "strand", Category: models.TagCategoryMechanic}}, []string{"tags[0].name"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFields(t, validateTags(tt.tags), tt.fields...)
+		})
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	got := normalizeTags([]models.Tag{{Name: "  Harvest  Farming "}, {Name: "harvest farming"}, {Name: "Strand"}})
+
+	names := make([]string, len(got))
+	for i, tag := range got {
+		names[i] = tag.Name
+	}
+
+	if want := []string{"harvest farming", "strand"}; !slices.Equal(names, want) {
+		t.Errorf("normalizeTags() = %v, want %v", names, want)
+	}
+}
+
+func TestValidateTable(t *testing.T) {
+	tests := []struct {
+		name   string
+		table  models.StrategyTable
+		fields []string
+	}{
+		{"drops", models.StrategyTable{Type: models.TableTypeDrops, Scale: 1}, nil},
+		{"modifiers", models.StrategyTable{Type: models.TableTypeModifiers, Scale: 2.5}, nil},
+		{"unknown type", models.StrategyTable{Type: "loot", Scale: 1}, []string{"type"}},
+		{"long title", models.StrategyTable{Type: models.TableTypeDrops, Title: strings.Repeat("a", maxTableTitleLength+1), Scale: 1}, []string{"title"}},
+		{"scale outside modifiers", models.StrategyTable{Type: models.TableTypeDrops, Scale: 2}, []string{"scale"}},
+		{"zero modifiers scale", models.StrategyTable{Type: models.TableTypeModifiers}, []string{"scale"}},
+		{"modifiers scale too large", models.StrategyTable{Type: models.TableTypeModifiers, Scale: maxTableScale + 1}, []string{"scale"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFields(t, validateTable(tt.table), tt.fields...)
+		})
+	}
+}
+
+func TestValidateItem(t *testing.T) {
+	negative := -1.0
+	valid := models.StrategyItem{ItemID: "orb", Amount: 1, Role: models.ItemRoleOutput, DropChance: 0.5}
+
+	tests := []struct {
+		name   string
+		mutate func(i *models.StrategyItem)
+		fields []string
+	}{
+		{"valid", func(i *models.StrategyItem) {}, nil},
+		{"missing item", func(i *models.StrategyItem) { i.ItemID = "" }, []string{"item_id"}},
+		{"zero amount", func(i *models.StrategyItem) { i.Amount = 0 }, []string{"amount"}},
+		{"amount too large", func(i *models.StrategyItem) { i.Amount = maxItemAmount + 1 }, []string{"amount"}},
+		{"unknown role", func(i *models.StrategyItem) { i.Role = "loot" }, []string{"role"}},
+		{"negative chance", func(i *models.StrategyItem) { i.DropChance = -0.1 }, []string{"drop_chance"}},
+		{"chance above 1", func(i *models.StrategyItem) { i.DropChance = 1.1 }, []string{"drop_chance"}},
+		{"negative override", func(i *models.StrategyItem) { i.PriceOverride = &negative }, []string{"price_override"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := valid
+			tt.mutate(&item)
+			assertFields(t, validateItem(item), tt.fields...)
+		})
+	}
+}
+
+func TestValidateItemKind(t *testing.T) {
+	inputs, _ := models.LookupTableKind(models.TableTypeInputs)
+	eitherOr, _ := models.LookupTableKind(models.TableTypeEitherOr)
+
+	tests := []struct {
+		name   string
+		errs   Errors
+		item   models.StrategyItem
+		kind   models.TableKind
+		fields []string
+	}{
+		{"matching role", Errors{}, models.StrategyItem{Role: models.ItemRoleInput}, inputs, nil},
+		{"wrong role", Errors{}, models.StrategyItem{Role: models.ItemRoleOutput}, inputs, []string{"x.role"}},
+		{"role already reported", Errors{"x.role": "must be input or output"}, models.StrategyItem{Role: "loot"}, inputs, []string{"x.role"}},
+		{"pair not allowed", Errors{}, models.StrategyItem{Role: models.ItemRoleInput, Pair: 2}, inputs, []string{"x.pair"}},
+		{"pair allowed", Errors{}, models.StrategyItem{Role: models.ItemRoleOutput, Pair: 2}, eitherOr, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validateItemKind(tt.errs, "x.", tt.item, tt.kind)
+			assertFields(t, tt.errs, tt.fields...)
+		})
+	}
+}
+
+func TestValidatePair(t *testing.T) {
+	siblings := []models.StrategyItem{
+		{SID: 1, DropChance: 0.4},
+		{SID: 2, DropChance: 0.4, Pair: 1},
+		{SID: 3, DropChance: 0.9},
+	}
+
+	tests := []struct {
+		name  string
+		item  models.StrategyItem
+		valid bool
+	}{
+		{"unpaired", models.StrategyItem{DropChance: 1}, true},
+		{"itself", models.StrategyItem{SID: 4, Pair: 4, DropChance: 0.1}, false},
+		{"other table", models.StrategyItem{Pair: 9, DropChance: 0.1}, false},
+		{"group fits", models.StrategyItem{Pair: 2, DropChance: 0.2}, true},
+		{"group exceeds 1", models.StrategyItem{Pair: 2, DropChance: 0.3}, false},
+		{"pair with an unpaired item", models.StrategyItem{Pair: 3, DropChance: 0.1}, true},
+		{"pair with an unpaired item exceeds 1", models.StrategyItem{Pair: 3, DropChance: 0.2}, false},
+		{"update within the group", models.StrategyItem{SID: 2, Pair: 1, DropChance: 0.6}, true},
+		{"update exceeding the group", models.StrategyItem{SID: 2, Pair: 1, DropChance: 0.7}, false},
+		{"update unpairing", models.StrategyItem{SID: 2, DropChance: 1}, true},
+		{"float32 chances adding up to 1", models.StrategyItem{Pair: 2, DropChance: float32(0.1) + float32(0.1)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := Errors{}
+			validatePair(errs, "pair", tt.item, siblings)
+
+			if _, invalid := errs["pair"]; invalid == tt.valid {
+				t.Errorf("validatePair() = %v, want valid %v", errs, tt.valid)
+			}
+		})
+	}
+}
+
+func TestPairGroupChance(t *testing.T) {
+	siblings := []models.StrategyItem{
+		{SID: 1, DropChance: 0.1},
+		{SID: 2, DropChance: 0.2, Pair: 1},
+		{SID: 3, DropChance: 0.3, Pair: 2},
+		{SID: 4, DropChance: 0.4},
+	}
+
+	tests := []struct {
+		name string
+		item models.StrategyItem
+		want float64
+	}{
+		{"new unpaired item", models.StrategyItem{DropChance: 0.5}, 0.5},
+		{"new item joins a chain", models.StrategyItem{Pair: 3, DropChance: 0.05}, 0.65},
+		{"existing item of a chain", models.StrategyItem{SID: 1, DropChance: 0.1}, 0.6},
+		{"replaced sibling counts once", models.StrategyItem{SID: 3, Pair: 2, DropChance: 0.05}, 0.35},
+		{"unlinked sibling", models.StrategyItem{SID: 4, DropChance: 0.4}, 0.4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pairGroupChance(tt.item, siblings)
+			if diff := got - tt.want; diff > 1e-6 || diff < -1e-6 {
+				t.Errorf("pairGroupChance() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	tests := []struct {
+		name   string
+		tables []models.SnapshotTable
+		fields []string
+	}{
+		{
+			name: "valid",
+			tables: []models.SnapshotTable{{
+				StrategyTable: models.StrategyTable{Type: models.TableTypeEitherOr},
+				Items: []models.StrategyItem{
+					{SID: 1, ItemID: "orb", Amount: 1, Role: models.ItemRoleOutput, DropChance: 0.5},
+					{SID: 2, ItemID: "scarab", Amount: 1, Role: models.ItemRoleOutput, DropChance: 0.5, Pair: 1},
+				},
+			}},
+		},
+		{
+			name: "duplicate and missing ids",
+			tables: []models.SnapshotTable{
+				{
+					StrategyTable: models.StrategyTable{Type: models.TableTypeInputs},
+					Items:         []models.StrategyItem{{SID: 1, ItemID: "map", Amount: 1, Role: models.ItemRoleInput}},
+				},
+				{
+					StrategyTable: models.StrategyTable{Type: models.TableTypeDrops},
+					Items: []models.StrategyItem{
+						{SID: 1, ItemID: "orb", Amount: 1, Role: models.ItemRoleOutput, DropChance: 1},
+						{ItemID: "orb", Amount: 1, Role: models.ItemRoleOutput, DropChance: 1},
+					},
+				},
+			},
+			fields: []string{"tables[1].items[0].id", "tables[1].items[1].id"},
+		},
+		{
+			name: "table and item rules",
+			tables: []models.SnapshotTable{{
+				StrategyTable: models.StrategyTable{Type: models.TableTypeInputs, Scale: 2},
+				Items:         []models.StrategyItem{{SID: 1, ItemID: "map", Role: models.ItemRoleOutput, Pair: 1}},
+			}},
+			fields: []string{"tables[0].scale", "tables[0].items[0].amount", "tables[0].items[0].role", "tables[0].items[0].pair"},
+		},
+		{
+			name: "either-or group exceeds 1",
+			tables: []models.SnapshotTable{{
+				StrategyTable: models.StrategyTable{Type: models.TableTypeEitherOr},
+				Items: []models.StrategyItem{
+					{SID: 1, ItemID: "orb", Amount: 1, Role: models.ItemRoleOutput, DropChance: 0.6},
+					{SID: 2, ItemID: "scarab", Amount: 1, Role: models.ItemRoleOutput, DropChance: 0.6, Pair: 1},
+				},
+			}},
+			fields: []string{"tables[0].items[0].pair", "tables[0].items[1].pair"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := models.StrategyTemplate{Name: "Harvest", Tables: tt.tables}
+			assertFields(t, validateTemplate(&template), tt.fields...)
+		})
+	}
+}